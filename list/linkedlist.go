@@ -137,9 +137,18 @@ func (l *LinkedList) String() string {
 	return sb.String()
 }
 
+// Sort the list in ascending order stably, every node.Data must implement dsa.Item
 func (l *LinkedList) Sort() {
-	// todo linked list
-	panic("not implement")
+	l.SortFunc(func(a interface{}, b interface{}) bool {
+		return a.(dsa.Item).Less(b.(dsa.Item))
+	})
+}
+
+// SortFunc sorts the list stably in the order of less, by natural merge sort.
+// Nodes are relinked, not copied, so the nodes held by callers stay valid.
+// o(n) for sorted or reversed lists, o(nlog(r)) for r runs, o(1) extra space.
+func (l *LinkedList) SortFunc(less func(a interface{}, b interface{}) bool) {
+	l.mergeSort(less)
 }
 
 func (l *LinkedList) Remove(q *LinkedNode) {
@@ -166,7 +175,94 @@ func (l *LinkedList) Traverse(f func(node *LinkedNode)) {
 	}
 }
 
-func (l LinkedList) mergeSort() {
-	// todo linked list
-	panic("not implement")
+// mergeSort detaches the nodes as a chain linked by succ only, merges the adjacent runs pass by pass
+// until one run is left, then restores pred.
+// The first pass reverses the strictly descending runs, so reversed lists are sorted in one pass.
+func (l *LinkedList) mergeSort(less func(a interface{}, b interface{}) bool) {
+	if l.size < 2 {
+		return
+	}
+	l.trailer.pred.succ = nil
+	head := l.header.succ
+	for first := true; ; first = false {
+		var merged, tail *LinkedNode // the chain of this pass
+		runs := 0
+		for head != nil {
+			var a, b *LinkedNode
+			a, head = takeRun(head, less, first)
+			if head != nil {
+				b, head = takeRun(head, less, first)
+			}
+			h, t := mergeRuns(a, b, less)
+			if tail == nil {
+				merged = h
+			} else {
+				tail.succ = h
+			}
+			tail = t
+			runs++
+		}
+		head = merged
+		if runs == 1 {
+			break
+		}
+	}
+
+	pred := l.header
+	for nd := head; nd != nil; nd = nd.succ {
+		pred.succ = nd
+		nd.pred = pred
+		pred = nd
+	}
+	pred.succ = l.trailer
+	l.trailer.pred = pred
+}
+
+// takeRun cuts the longest non-descending run at the head of the chain, or the strictly descending
+// one reversed if allowed, returns it and the rest
+func takeRun(head *LinkedNode, less func(a interface{}, b interface{}) bool, reverse bool) (*LinkedNode, *LinkedNode) {
+	nd := head
+	if reverse && nd.succ != nil && less(nd.succ.Data, nd.Data) {
+		var run *LinkedNode
+		for {
+			next := nd.succ
+			nd.succ = run
+			run = nd
+			if next == nil || !less(next.Data, nd.Data) {
+				return run, next
+			}
+			nd = next
+		}
+	}
+	for nd.succ != nil && !less(nd.succ.Data, nd.Data) {
+		nd = nd.succ
+	}
+	rest := nd.succ
+	nd.succ = nil
+	return head, rest
+}
+
+// mergeRuns merges the sorted chains a and b, the equal ones of a go first, returns the head and the
+// tail of the merged chain
+func mergeRuns(a *LinkedNode, b *LinkedNode, less func(a interface{}, b interface{}) bool) (*LinkedNode, *LinkedNode) {
+	dummy := &LinkedNode{}
+	tail := dummy
+	for a != nil && b != nil {
+		if less(b.Data, a.Data) {
+			tail.succ = b
+			b = b.succ
+		} else {
+			tail.succ = a
+			a = a.succ
+		}
+		tail = tail.succ
+	}
+	if a == nil {
+		a = b
+	}
+	tail.succ = a
+	for tail.succ != nil {
+		tail = tail.succ
+	}
+	return dummy.succ, tail
 }
@@ -0,0 +1,74 @@
+package list
+
+import (
+	"github.com/joexzh/dsa"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+type record struct {
+	k int
+	i int
+}
+
+func TestLinkedList_Sort(t *testing.T) {
+	gens := map[string]func(i int, n int) int{
+		"Random":   func(i int, n int) int { return rand.Intn(n/4 + 1) },
+		"Sorted":   func(i int, n int) int { return i },
+		"Reversed": func(i int, n int) int { return n - i },
+		"Runs":     func(i int, n int) int { return i%50 + (i/50%2)*(100-2*(i%50)) }, // up and down runs
+	}
+	for name, gen := range gens {
+		for _, n := range []int{0, 1, 2, 3, 10, 100, 1000} {
+			l := NewLinkedList()
+			expected := make([]record, n)
+			nodes := make(map[*LinkedNode]bool)
+			for i := range expected {
+				expected[i] = record{gen(i, n), i}
+				l.InsertEnd(expected[i])
+				nodes[l.Last()] = true
+			}
+			sort.SliceStable(expected, func(i, j int) bool { return expected[i].k < expected[j].k })
+
+			l.SortFunc(func(a interface{}, b interface{}) bool { return a.(record).k < b.(record).k })
+			if l.Size() != n {
+				t.Fatalf("%s of %d: expected size %d, got %d", name, n, n, l.Size())
+			}
+			i := 0
+			for nd := l.First(); nd.Valid(); nd = nd.Succ() {
+				if nd.Data != expected[i] {
+					t.Fatalf("%s of %d: index %d expected %v, got %v", name, n, i, expected[i], nd.Data)
+				}
+				if !nodes[nd] || nd.Succ().Pred() != nd {
+					t.Fatalf("%s of %d: node %d is not relinked", name, n, i)
+				}
+				i++
+			}
+			if i != n {
+				t.Fatalf("%s of %d: expected %d nodes, got %d", name, n, n, i)
+			}
+			for nd := l.Last(); nd.Valid(); nd = nd.Pred() {
+				i--
+			}
+			if i != 0 {
+				t.Fatalf("%s of %d: expected the same nodes backward", name, n)
+			}
+		}
+	}
+}
+
+func TestLinkedList_SortItem(t *testing.T) {
+	l := NewLinkedList()
+	for _, k := range rand.Perm(100) {
+		l.InsertEnd(dsa.Int64(k))
+	}
+	l.Sort()
+	k := dsa.Int64(0)
+	l.Traverse(func(nd *LinkedNode) {
+		if nd.Data != k {
+			t.Fatalf("expected %v, got %v", k, nd.Data)
+		}
+		k++
+	})
+}
@@ -0,0 +1,188 @@
+package piority
+
+var _ AddressableQueue = (*BinomialHeap)(nil)
+
+// BinomialHeap is a forest of binomial trees of distinct orders, like the binary digits of its size.
+// A tree of order k has 2^k nodes, its root has children of order 0..k-1.
+// Push is o(1) amortized, Pop, Merge, DecreaseKey and Remove are o(logn).
+//
+// Nodes swap elements when sifting up, so handles are separate from nodes.
+type BinomialHeap struct {
+	trees []*binomialNode // trees[k] is the tree of order k, or nil
+	min   *binomialNode   // a root of the least element
+	size  int
+	less  Less
+	owner *owner // of the items of h
+}
+
+type binomialNode struct {
+	item   *binomialItem
+	parent *binomialNode
+	child  []*binomialNode // child[i] is of order i
+}
+
+type binomialItem struct {
+	v     interface{}
+	node  *binomialNode // nil if removed
+	owner *owner
+}
+
+func (x *binomialItem) Value() interface{} {
+	return x.v
+}
+
+// NewBinomialHeap returns an empty heap ordered by less, by ItemLess if nil
+func NewBinomialHeap(less Less) BinomialHeap {
+	if less == nil {
+		less = ItemLess
+	}
+	return BinomialHeap{less: less, owner: &owner{}}
+}
+
+func (h *BinomialHeap) Size() int {
+	return h.size
+}
+
+func (h *BinomialHeap) Empty() bool {
+	return h.size == 0
+}
+
+// Push v to the heap
+func (h *BinomialHeap) Push(v interface{}) {
+	h.Insert(v)
+}
+
+// Insert pushes v, and returns its handle. It's adding 1 to the size in binary.
+func (h *BinomialHeap) Insert(v interface{}) Handle {
+	it := &binomialItem{v: v, owner: h.owner}
+	it.node = &binomialNode{item: it}
+	h.add(it.node)
+	h.size++
+	if h.min == nil || h.less(v, h.min.item.v) {
+		h.min = it.node
+	}
+	for h.min.parent != nil { // linked under an equal one
+		h.min = h.min.parent
+	}
+	return it
+}
+
+// Peek returns the least element, nil if empty
+func (h *BinomialHeap) Peek() interface{} {
+	if h.min == nil {
+		return nil
+	}
+	return h.min.item.v
+}
+
+// Pop removes and returns the least element, nil if empty
+func (h *BinomialHeap) Pop() interface{} {
+	if h.min == nil {
+		return nil
+	}
+	return h.removeRoot(h.min)
+}
+
+// DecreaseKey changes the element of x to v, which must not be greater than the old one, otherwise panic
+func (h *BinomialHeap) DecreaseKey(x Handle, v interface{}) {
+	it := h.item(x)
+	if h.less(it.v, v) {
+		panic("BinomialHeap.DecreaseKey: new value is greater")
+	}
+	it.v = v
+	nd := h.up(it.node, false)
+	if nd.parent == nil && h.less(v, h.min.item.v) {
+		h.min = nd
+	}
+}
+
+// Remove the element of x, and returns it. x is sifted up to root unconditionally, then removed.
+func (h *BinomialHeap) Remove(x Handle) interface{} {
+	it := h.item(x)
+	return h.removeRoot(h.up(it.node, true))
+}
+
+// Merge moves all elements of other into h, other becomes empty. Both must be in the same order.
+func (h *BinomialHeap) Merge(other *BinomialHeap) {
+	if h == other {
+		return
+	}
+	for _, t := range other.trees {
+		if t != nil {
+			h.add(t)
+		}
+	}
+	h.size += other.size
+	other.trees, other.min, other.size = nil, nil, 0
+	other.owner.parent, other.owner = h.owner, &owner{} // the moved items are of h now
+	h.updateMin()
+}
+
+func (h *BinomialHeap) item(x Handle) *binomialItem {
+	it, ok := x.(*binomialItem)
+	if !ok || it.node == nil || it.owner.find() != h.owner {
+		panic("BinomialHeap: invalid handle")
+	}
+	return it
+}
+
+// add tree t, carries to the higher orders like binary addition
+func (h *BinomialHeap) add(t *binomialNode) {
+	for k := len(t.child); ; k++ {
+		if k == len(h.trees) {
+			h.trees = append(h.trees, nil)
+		}
+		if h.trees[k] == nil {
+			h.trees[k] = t
+			return
+		}
+		t = h.link(h.trees[k], t)
+		h.trees[k] = nil
+	}
+}
+
+// link two trees of the same order, the greater root becomes the last child of the other
+func (h *BinomialHeap) link(a *binomialNode, b *binomialNode) *binomialNode {
+	if h.less(b.item.v, a.item.v) {
+		a, b = b, a
+	}
+	b.parent = a
+	a.child = append(a.child, b)
+	return a
+}
+
+// removeRoot removes the root r, its children are added back as trees
+func (h *BinomialHeap) removeRoot(r *binomialNode) interface{} {
+	h.trees[len(r.child)] = nil
+	for _, c := range r.child {
+		c.parent = nil
+		h.add(c)
+	}
+	for len(h.trees) > 0 && h.trees[len(h.trees)-1] == nil {
+		h.trees = h.trees[:len(h.trees)-1]
+	}
+	h.size--
+	h.updateMin()
+	it := r.item
+	it.node = nil
+	return it.v
+}
+
+// up sifts the item of x up by swapping items with parents, while it's less, or always if force.
+// Returns the node it stops at.
+func (h *BinomialHeap) up(x *binomialNode, force bool) *binomialNode {
+	for p := x.parent; p != nil && (force || h.less(x.item.v, p.item.v)); x, p = p, p.parent {
+		x.item, p.item = p.item, x.item
+		x.item.node, p.item.node = x, p
+	}
+	return x
+}
+
+func (h *BinomialHeap) updateMin() {
+	h.min = nil
+	for _, t := range h.trees {
+		if t != nil && (h.min == nil || h.less(t.item.v, h.min.item.v)) {
+			h.min = t
+		}
+	}
+}
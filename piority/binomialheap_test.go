@@ -0,0 +1,51 @@
+package piority
+
+import (
+	"testing"
+)
+
+// checkBinomial checks the orders, heap order and links of tree x of order k, returns the number of nodes
+func checkBinomial(t *testing.T, x *binomialNode, k int, less Less) int {
+	if len(x.child) != k || x.item.node != x {
+		t.Fatalf("node %v expected order %d, got %d", x.item.v, k, len(x.child))
+	}
+	n := 1
+	for i, c := range x.child {
+		if c.parent != x || less(c.item.v, x.item.v) {
+			t.Fatalf("child %v of %v is broken", c.item.v, x.item.v)
+		}
+		n += checkBinomial(t, c, i, less)
+	}
+	if n != 1<<k {
+		t.Fatalf("tree of order %d expected %d nodes, got %d", k, 1<<k, n)
+	}
+	return n
+}
+
+func TestBinomialHeap_Merge(t *testing.T) {
+	a, b := NewBinomialHeap(intLess), NewBinomialHeap(intLess)
+	va, vb := randomInts(500, 1000), randomInts(701, 1000)
+	for _, v := range va {
+		a.Push(v)
+	}
+	for _, v := range vb {
+		b.Push(v)
+	}
+	a.Merge(&b)
+	if !b.Empty() {
+		t.Fatalf("expected other empty after merge")
+	}
+	n := 0
+	for k, x := range a.trees {
+		if x != nil {
+			if x.parent != nil {
+				t.Fatalf("root %v has parent", x.item.v)
+			}
+			n += checkBinomial(t, x, k, intLess)
+		}
+	}
+	if n != a.Size() || n != len(va)+len(vb) {
+		t.Fatalf("expected %d nodes, got %d, size %d", len(va)+len(vb), n, a.Size())
+	}
+	popAll(t, "BinomialHeap", a.Pop, append(va, vb...))
+}
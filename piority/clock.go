@@ -0,0 +1,132 @@
+package piority
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the time source of DelayQueue, replaceable by MockClock in tests
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer sends the time to C once after its duration, unless stopped
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// SystemClock is the Clock of package time
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// MockClock only moves by Advance or Set, and fires its timers then. It's goroutine-safe.
+type MockClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers map[*mockTimer]bool
+}
+
+type mockTimer struct {
+	c     *MockClock
+	when  time.Time
+	ch    chan time.Time
+	fired bool
+}
+
+func NewMockClock(now time.Time) *MockClock {
+	return &MockClock{now: now, timers: make(map[*mockTimer]bool)}
+}
+
+func (c *MockClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer fires after d of mock time, immediately if d <= 0
+func (c *MockClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &mockTimer{c: c, when: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.fire(c.now)
+	} else {
+		c.timers[t] = true
+	}
+	return t
+}
+
+// Advance moves the clock forward by d
+func (c *MockClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(c.now.Add(d))
+}
+
+// Set moves the clock to now, which must not be before the current time
+func (c *MockClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now.Before(c.now) {
+		panic("MockClock.Set: time goes backward")
+	}
+	c.set(now)
+}
+
+// Timers returns the number of timers waiting to fire, to know when goroutines are blocked on them
+func (c *MockClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *MockClock) set(now time.Time) {
+	c.now = now
+	for t := range c.timers {
+		if !t.when.After(now) {
+			t.fire(now)
+			delete(c.timers, t)
+		}
+	}
+}
+
+func (t *mockTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *mockTimer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	delete(t.c.timers, t)
+	return !t.fired
+}
+
+func (t *mockTimer) fire(now time.Time) {
+	t.fired = true
+	t.ch <- now
+}
@@ -0,0 +1,114 @@
+package piority
+
+var _ PriorityQueue = (*DaryHeap)(nil)
+
+// DaryHeap is like BinaryHeap, but every node has d children, so the tree is log_d(n) high.
+// Push is o(log_d(n)), Pop is o(d*log_d(n)) but visits fewer cache lines for big queues.
+// Elements are kept in a slice of values without handles.
+type DaryHeap struct {
+	d    int
+	vals []interface{}
+	less Less
+}
+
+// NewDaryHeap returns an empty heap of arity d ordered by less, by ItemLess if nil. Panics if d < 2.
+func NewDaryHeap(d int, less Less) DaryHeap {
+	if d < 2 {
+		panic("DaryHeap arity must be at least 2")
+	}
+	if less == nil {
+		less = ItemLess
+	}
+	return DaryHeap{d: d, less: less}
+}
+
+// NewDaryHeapFrom builds a heap of vals by Floyd's heapify, o(n)
+func NewDaryHeapFrom(d int, vals []interface{}, less Less) DaryHeap {
+	h := NewDaryHeap(d, less)
+	h.vals = append([]interface{}(nil), vals...)
+	for i := (len(h.vals) - 2) / d; i >= 0; i-- { // from the parent of the last one
+		h.down(i)
+	}
+	return h
+}
+
+func (h *DaryHeap) Arity() int {
+	return h.d
+}
+
+func (h *DaryHeap) Size() int {
+	return len(h.vals)
+}
+
+func (h *DaryHeap) Empty() bool {
+	return len(h.vals) == 0
+}
+
+// Push v to the heap
+func (h *DaryHeap) Push(v interface{}) {
+	h.vals = append(h.vals, v)
+	h.up(len(h.vals) - 1)
+}
+
+// Peek returns the least element, nil if empty
+func (h *DaryHeap) Peek() interface{} {
+	if h.Empty() {
+		return nil
+	}
+	return h.vals[0]
+}
+
+// Pop removes and returns the least element, nil if empty
+func (h *DaryHeap) Pop() interface{} {
+	if h.Empty() {
+		return nil
+	}
+	v := h.vals[0]
+	last := len(h.vals) - 1
+	h.vals[0] = h.vals[last]
+	h.vals[last] = nil
+	h.vals = h.vals[:last]
+	h.down(0)
+	return v
+}
+
+// up moves the hole at i up until the parent is not greater, then puts the value there
+func (h *DaryHeap) up(i int) {
+	v := h.vals[i]
+	for i > 0 {
+		p := (i - 1) / h.d
+		if !h.less(v, h.vals[p]) {
+			break
+		}
+		h.vals[i] = h.vals[p]
+		i = p
+	}
+	h.vals[i] = v
+}
+
+// down moves the hole at i down to the least child until no child is less, then puts the value there
+func (h *DaryHeap) down(i int) {
+	n := len(h.vals)
+	if i >= n {
+		return
+	}
+	v := h.vals[i]
+	for {
+		first := h.d*i + 1
+		if first >= n {
+			break
+		}
+		m := first
+		for c := first + 1; c < first+h.d && c < n; c++ {
+			if h.less(h.vals[c], h.vals[m]) {
+				m = c
+			}
+		}
+		if !h.less(h.vals[m], v) {
+			break
+		}
+		h.vals[i] = h.vals[m]
+		i = m
+	}
+	h.vals[i] = v
+}
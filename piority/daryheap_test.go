@@ -0,0 +1,66 @@
+package piority
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDaryHeap(t *testing.T) {
+	for _, d := range []int{2, 3, 4, 8} {
+		h := NewDaryHeap(d, intLess)
+		testPriorityQueue(t, "DaryHeap", &h)
+	}
+}
+
+func TestNewDaryHeapFrom(t *testing.T) {
+	for _, d := range []int{2, 3, 5} {
+		for _, n := range []int{0, 1, 2, 6, 100, 1000} {
+			vals := randomInts(n, 100)
+			ivals := make([]interface{}, n)
+			for i, v := range vals {
+				ivals[i] = v
+			}
+			h := NewDaryHeapFrom(d, ivals, intLess)
+			for i := 1; i < h.Size(); i++ {
+				if p := (i - 1) / d; intLess(h.vals[i], h.vals[p]) {
+					t.Fatalf("d %d node %d %v is less than its parent %v", d, i, h.vals[i], h.vals[p])
+				}
+			}
+			popAll(t, "DaryHeap", h.Pop, vals)
+		}
+	}
+}
+
+const benchSize = 1 << 20
+
+// benchmarkPushPop keeps a big queue, then pops and pushes in turns
+func benchmarkPushPop(b *testing.B, pq PriorityQueue) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < benchSize; i++ {
+		pq.Push(r.Int())
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pq.Push(pq.Pop().(int) + r.Intn(benchSize))
+	}
+}
+
+func BenchmarkBinaryHeap_PushPop(b *testing.B) {
+	h := NewBinaryHeap(intLess)
+	benchmarkPushPop(b, &h)
+}
+
+func BenchmarkDaryHeap2_PushPop(b *testing.B) {
+	h := NewDaryHeap(2, intLess)
+	benchmarkPushPop(b, &h)
+}
+
+func BenchmarkDaryHeap4_PushPop(b *testing.B) {
+	h := NewDaryHeap(4, intLess)
+	benchmarkPushPop(b, &h)
+}
+
+func BenchmarkDaryHeap8_PushPop(b *testing.B) {
+	h := NewDaryHeap(8, intLess)
+	benchmarkPushPop(b, &h)
+}
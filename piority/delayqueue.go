@@ -0,0 +1,200 @@
+package piority
+
+import (
+	"context"
+	"github.com/joexzh/dsa/list"
+	"sync"
+	"time"
+)
+
+// Delayed is an item scheduled in a DelayQueue, also the handle to cancel it
+type Delayed struct {
+	v        interface{}
+	deadline time.Time
+	q        *DelayQueue // nil if taken or canceled
+
+	handle Handle           // of heapStore
+	tick   uint64           // of timingWheel, the tick it expires at
+	slot   *list.LinkedList // of timingWheel, the slot or the ready list it's in
+	node   *list.LinkedNode // of timingWheel, in slot
+}
+
+func (d *Delayed) Value() interface{} {
+	return d.v
+}
+
+func (d *Delayed) Deadline() time.Time {
+	return d.deadline
+}
+
+// delayStore keeps the items by deadline, not goroutine-safe
+type delayStore interface {
+	add(d *Delayed)
+	remove(d *Delayed)
+	// poll removes and returns an item whose deadline is not after now, nil if none
+	poll(now time.Time) *Delayed
+	// next returns the time to poll again, false if empty
+	next(now time.Time) (time.Time, bool)
+	size() int
+}
+
+// DelayQueue releases items when their deadlines pass, it's goroutine-safe.
+// NewDelayQueue keeps items in a BinaryHeap, o(logn) to schedule and cancel, and releases them in
+// the order of deadlines. NewTimingWheelQueue uses a hierarchical timing wheel, o(1) to schedule
+// and cancel, but the deadlines are rounded up to its tick, items of the same tick are released in
+// any order.
+type DelayQueue struct {
+	mu    sync.Mutex
+	clock Clock
+	store delayStore
+	wake  chan struct{} // closed and replaced when a new item is scheduled, to wake all waiting Take
+}
+
+// NewDelayQueue returns a heap based queue, by the system clock if clock is nil
+func NewDelayQueue(clock Clock) *DelayQueue {
+	return newDelayQueue(clock, newHeapStore())
+}
+
+// NewTimingWheelQueue returns a timing wheel based queue of tick, by the system clock if clock is nil.
+// Panics if tick <= 0.
+func NewTimingWheelQueue(clock Clock, tick time.Duration) *DelayQueue {
+	if tick <= 0 {
+		panic("TimingWheelQueue tick must be positive")
+	}
+	if clock == nil {
+		clock = SystemClock()
+	}
+	return newDelayQueue(clock, newTimingWheel(clock.Now(), tick))
+}
+
+func newDelayQueue(clock Clock, store delayStore) *DelayQueue {
+	if clock == nil {
+		clock = SystemClock()
+	}
+	return &DelayQueue{clock: clock, store: store, wake: make(chan struct{})}
+}
+
+// Len returns the number of items scheduled, expired or not
+func (q *DelayQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.store.size()
+}
+
+// Schedule v to be released at deadline, returns the handle to cancel it
+func (q *DelayQueue) Schedule(v interface{}, deadline time.Time) *Delayed {
+	d := &Delayed{v: v, deadline: deadline, q: q}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.store.add(d)
+	close(q.wake)
+	q.wake = make(chan struct{})
+	return d
+}
+
+// ScheduleAfter schedules v to be released after delay from now
+func (q *DelayQueue) ScheduleAfter(v interface{}, delay time.Duration) *Delayed {
+	return q.Schedule(v, q.clock.Now().Add(delay))
+}
+
+// Cancel d, returns false if it's already released or canceled
+func (q *DelayQueue) Cancel(d *Delayed) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if d.q != q {
+		return false
+	}
+	q.store.remove(d)
+	d.q = nil
+	return true
+}
+
+// Poll returns an expired item without blocking, false if none
+func (q *DelayQueue) Poll() (interface{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if d := q.poll(); d != nil {
+		return d.v, true
+	}
+	return nil, false
+}
+
+// Take blocks until an item expires and returns it, or returns the error of ctx when it's done
+func (q *DelayQueue) Take(ctx context.Context) (interface{}, error) {
+	for {
+		q.mu.Lock()
+		if d := q.poll(); d != nil {
+			q.mu.Unlock()
+			return d.v, nil
+		}
+		now := q.clock.Now()
+		next, ok := q.store.next(now)
+		wake := q.wake
+		q.mu.Unlock()
+
+		var timer Timer
+		var timeout <-chan time.Time // nil blocks forever
+		if ok {
+			timer = q.clock.NewTimer(next.Sub(now))
+			timeout = timer.C()
+		}
+		select {
+		case <-ctx.Done():
+		case <-wake:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (q *DelayQueue) poll() *Delayed {
+	d := q.store.poll(q.clock.Now())
+	if d != nil {
+		d.q = nil
+	}
+	return d
+}
+
+type heapStore struct {
+	h BinaryHeap
+}
+
+func newHeapStore() *heapStore {
+	return &heapStore{h: NewBinaryHeap(func(a interface{}, b interface{}) bool {
+		return a.(*Delayed).deadline.Before(b.(*Delayed).deadline)
+	})}
+}
+
+func (s *heapStore) add(d *Delayed) {
+	d.handle = s.h.Insert(d)
+}
+
+func (s *heapStore) remove(d *Delayed) {
+	s.h.Remove(d.handle)
+	d.handle = nil
+}
+
+func (s *heapStore) poll(now time.Time) *Delayed {
+	if s.h.Empty() || s.h.Peek().(*Delayed).deadline.After(now) {
+		return nil
+	}
+	d := s.h.Pop().(*Delayed)
+	d.handle = nil
+	return d
+}
+
+func (s *heapStore) next(now time.Time) (time.Time, bool) {
+	if s.h.Empty() {
+		return time.Time{}, false
+	}
+	return s.h.Peek().(*Delayed).deadline, true
+}
+
+func (s *heapStore) size() int {
+	return s.h.Size()
+}
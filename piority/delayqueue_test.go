@@ -0,0 +1,204 @@
+package piority
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var newTestQueues = map[string]func(clock Clock) *DelayQueue{
+	"DelayQueue": NewDelayQueue,
+	"TimingWheelQueue": func(clock Clock) *DelayQueue {
+		return NewTimingWheelQueue(clock, time.Millisecond)
+	},
+	"TimingWheelQueue7s": func(clock Clock) *DelayQueue {
+		return NewTimingWheelQueue(clock, 7*time.Second)
+	},
+}
+
+// testDelayQueue schedules items of random deadlines in span, cancels some, moves the clock by random
+// steps, and checks the released ones by their deadlines
+func testDelayQueue(t *testing.T, name string, q *DelayQueue, clock *MockClock, tick time.Duration, span time.Duration) {
+	live := make(map[*Delayed]bool)
+	all := make([]*Delayed, 0)
+	for i := 0; i < 3000; i++ {
+		d := q.Schedule(i, clock.Now().Add(time.Duration(rand.Int63n(int64(span)))-span/100))
+		live[d] = true
+		all = append(all, d)
+		if i%5 == 0 {
+			c := all[rand.Intn(len(all))]
+			if ok := q.Cancel(c); ok != live[c] {
+				t.Fatalf("%s: cancel expected %v, got %v", name, live[c], ok)
+			}
+			delete(live, c)
+		}
+		if i%10 == 0 {
+			clock.Advance(time.Duration(rand.Int63n(int64(span / 100))))
+		}
+		if i%7 == 0 || i > 2000 {
+			if i > 2000 {
+				clock.Advance(span / 500)
+			}
+			now := clock.Now()
+			released := make([]*Delayed, 0)
+			for {
+				v, ok := q.Poll()
+				if !ok {
+					break
+				}
+				d := all[v.(int)]
+				if !live[d] {
+					t.Fatalf("%s: released %v is canceled or released", name, v)
+				}
+				if d.Deadline().After(now) {
+					t.Fatalf("%s: released %v early, deadline %v now %v", name, v, d.Deadline(), now)
+				}
+				delete(live, d)
+				released = append(released, d)
+			}
+			if tick == 0 && !sort.SliceIsSorted(released, func(i, j int) bool {
+				return released[i].Deadline().Before(released[j].Deadline())
+			}) {
+				t.Fatalf("%s: expected released in order of deadline", name)
+			}
+			due := now // items not after due must be released
+			if tick > 0 {
+				due = epoch.Add(now.Sub(epoch) / tick * tick)
+			}
+			for d := range live {
+				if !d.Deadline().After(due) {
+					t.Fatalf("%s: %v not released, deadline %v now %v", name, d.Value(), d.Deadline(), now)
+				}
+			}
+		}
+		if q.Len() != len(live) {
+			t.Fatalf("%s: expected len %d, got %d", name, len(live), q.Len())
+		}
+	}
+}
+
+func TestDelayQueue(t *testing.T) {
+	for _, span := range []time.Duration{time.Second, time.Hour, 24 * 365 * time.Hour} {
+		clock := NewMockClock(epoch)
+		testDelayQueue(t, "DelayQueue", NewDelayQueue(clock), clock, 0, span)
+		clock = NewMockClock(epoch)
+		testDelayQueue(t, "TimingWheelQueue", NewTimingWheelQueue(clock, time.Millisecond), clock, time.Millisecond, span)
+		clock = NewMockClock(epoch)
+		testDelayQueue(t, "TimingWheelQueue7s", NewTimingWheelQueue(clock, 7*time.Second), clock, 7*time.Second, span)
+	}
+}
+
+func TestDelayQueue_Take(t *testing.T) {
+	for name, newQueue := range newTestQueues {
+		clock := NewMockClock(epoch)
+		q := newQueue(clock)
+		q.ScheduleAfter("a", time.Hour)
+		got := make(chan interface{})
+		go func() {
+			v, err := q.Take(context.Background())
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+			got <- v
+		}()
+		for clock.Timers() == 0 { // Take is waiting for the deadline
+			time.Sleep(time.Millisecond)
+		}
+		q.ScheduleAfter("b", 0) // an expired one wakes it, the clock is at the start tick of wheels
+		if v := <-got; v != "b" {
+			t.Fatalf("%s: expected b, got %v", name, v)
+		}
+
+		go func() {
+			v, _ := q.Take(context.Background())
+			got <- v
+		}()
+		for clock.Timers() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		clock.Advance(time.Hour + 7*time.Second)
+		if v := <-got; v != "a" {
+			t.Fatalf("%s: expected a, got %v", name, v)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			_, err := q.Take(ctx)
+			got <- err
+		}()
+		cancel()
+		if err := <-got; err != context.Canceled {
+			t.Fatalf("%s: expected canceled, got %v", name, err)
+		}
+	}
+}
+
+func TestDelayQueue_ConcurrentTake(t *testing.T) {
+	for name, q := range map[string]*DelayQueue{
+		"DelayQueue":       NewDelayQueue(nil),
+		"TimingWheelQueue": NewTimingWheelQueue(nil, time.Millisecond),
+	} {
+		const n = 200
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		seen := make(map[interface{}]bool)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					v, err := q.Take(ctx)
+					if err != nil {
+						return
+					}
+					mu.Lock()
+					if seen[v] {
+						t.Errorf("%s: %v taken twice", name, v)
+					}
+					seen[v] = true
+					if len(seen) == n {
+						cancel()
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		for i := 0; i < n; i++ {
+			q.ScheduleAfter(i, time.Duration(rand.Intn(20))*time.Millisecond)
+		}
+		wg.Wait()
+		cancel()
+		if len(seen) != n {
+			t.Fatalf("%s: expected %d taken, got %d", name, n, len(seen))
+		}
+	}
+}
+
+func BenchmarkDelayQueue_ScheduleCancel(b *testing.B) {
+	benchmarkScheduleCancel(b, NewDelayQueue(NewMockClock(epoch)))
+}
+
+func BenchmarkTimingWheelQueue_ScheduleCancel(b *testing.B) {
+	benchmarkScheduleCancel(b, NewTimingWheelQueue(NewMockClock(epoch), time.Millisecond))
+}
+
+// benchmarkScheduleCancel keeps a million timeouts, and schedules and cancels one each time
+func benchmarkScheduleCancel(b *testing.B, q *DelayQueue) {
+	r := rand.New(rand.NewSource(1))
+	ds := make([]*Delayed, 1<<20)
+	for i := range ds {
+		ds[i] = q.ScheduleAfter(i, time.Duration(r.Int63n(int64(time.Hour))))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j := i & (len(ds) - 1)
+		q.Cancel(ds[j])
+		ds[j] = q.ScheduleAfter(j, time.Duration(r.Int63n(int64(time.Hour))))
+	}
+}
@@ -0,0 +1,245 @@
+package piority
+
+import (
+	"math/bits"
+)
+
+var _ AddressableQueue = (*FibonacciHeap)(nil)
+
+// FibonacciHeap is a lazy list of heap-ordered trees. Push, Merge and DecreaseKey are o(1) amortized,
+// Pop consolidates the roots by linking the ones of the same degree, o(logn) amortized.
+// A node loses at most one child before it's cut as well (cascading cut), which keeps the size of
+// a tree of degree d at least F(d+2). Handles are the nodes.
+type FibonacciHeap struct {
+	min   *fibNode // in the circular list of roots
+	size  int
+	less  Less
+	owner *owner // of the nodes of h
+}
+
+type fibNode struct {
+	v       interface{}
+	parent  *fibNode
+	child   *fibNode // any one in the circular list of children
+	left    *fibNode
+	right   *fibNode
+	degree  int
+	mark    bool // lost a child since it became a child
+	removed bool
+	owner   *owner
+}
+
+func (x *fibNode) Value() interface{} {
+	return x.v
+}
+
+// NewFibonacciHeap returns an empty heap ordered by less, by ItemLess if nil
+func NewFibonacciHeap(less Less) FibonacciHeap {
+	if less == nil {
+		less = ItemLess
+	}
+	return FibonacciHeap{less: less, owner: &owner{}}
+}
+
+func (h *FibonacciHeap) Size() int {
+	return h.size
+}
+
+func (h *FibonacciHeap) Empty() bool {
+	return h.size == 0
+}
+
+// Push v to the heap
+func (h *FibonacciHeap) Push(v interface{}) {
+	h.Insert(v)
+}
+
+// Insert pushes v as a new root, and returns its handle, o(1)
+func (h *FibonacciHeap) Insert(v interface{}) Handle {
+	x := &fibNode{v: v, owner: h.owner}
+	x.left, x.right = x, x
+	h.addRoot(x)
+	h.size++
+	return x
+}
+
+// Peek returns the least element, nil if empty
+func (h *FibonacciHeap) Peek() interface{} {
+	if h.min == nil {
+		return nil
+	}
+	return h.min.v
+}
+
+// Pop removes and returns the least element, nil if empty
+func (h *FibonacciHeap) Pop() interface{} {
+	if h.min == nil {
+		return nil
+	}
+	x := h.min
+	for x.child != nil { // children become roots
+		c := x.child
+		if c.right == c {
+			x.child = nil
+		} else {
+			x.child = c.right
+		}
+		unlinkFib(c)
+		c.parent, c.mark = nil, false
+		spliceFib(x, c)
+	}
+	if x.right == x {
+		h.min = nil
+	} else {
+		h.min = x.right
+		unlinkFib(x)
+		h.consolidate()
+	}
+	h.size--
+	x.removed = true
+	x.left, x.right = nil, nil
+	return x.v
+}
+
+// DecreaseKey changes the element of x to v, which must not be greater than the old one, otherwise panic.
+// If the heap order is broken, x is cut to be a root, o(1) amortized.
+func (h *FibonacciHeap) DecreaseKey(x Handle, v interface{}) {
+	nd := h.node(x)
+	if h.less(nd.v, v) {
+		panic("FibonacciHeap.DecreaseKey: new value is greater")
+	}
+	nd.v = v
+	if p := nd.parent; p != nil && h.less(nd.v, p.v) {
+		h.cut(nd)
+		h.cascadingCut(p)
+	}
+	if h.less(nd.v, h.min.v) {
+		h.min = nd
+	}
+}
+
+// Remove the element of x, and returns it. x is cut to be a root and popped as the min.
+func (h *FibonacciHeap) Remove(x Handle) interface{} {
+	nd := h.node(x)
+	if p := nd.parent; p != nil {
+		h.cut(nd)
+		h.cascadingCut(p)
+	}
+	h.min = nd
+	return h.Pop()
+}
+
+// Merge moves all elements of other into h, other becomes empty, o(1). Both must be in the same order.
+func (h *FibonacciHeap) Merge(other *FibonacciHeap) {
+	if h == other || other.min == nil {
+		return
+	}
+	if h.min == nil {
+		h.min = other.min
+	} else {
+		spliceFib(h.min, other.min)
+		if h.less(other.min.v, h.min.v) {
+			h.min = other.min
+		}
+	}
+	h.size += other.size
+	other.min, other.size = nil, 0
+	other.owner.parent, other.owner = h.owner, &owner{} // the moved nodes are of h now
+}
+
+func (h *FibonacciHeap) node(x Handle) *fibNode {
+	nd, ok := x.(*fibNode)
+	if !ok || nd.removed || nd.owner.find() != h.owner {
+		panic("FibonacciHeap: invalid handle")
+	}
+	return nd
+}
+
+// addRoot adds the single node x to the root list, and updates min
+func (h *FibonacciHeap) addRoot(x *fibNode) {
+	if h.min == nil {
+		h.min = x
+		return
+	}
+	spliceFib(h.min, x)
+	if h.less(x.v, h.min.v) {
+		h.min = x
+	}
+}
+
+// consolidate links roots of the same degree until all degrees are distinct, and finds min
+func (h *FibonacciHeap) consolidate() {
+	byDegree := make([]*fibNode, bits.Len(uint(h.size))*3/2+2) // max degree is log_phi(n)
+	roots := make([]*fibNode, 0, len(byDegree))
+	x := h.min
+	for {
+		roots = append(roots, x)
+		if x = x.right; x == h.min {
+			break
+		}
+	}
+	for _, x := range roots {
+		unlinkFib(x)
+		for byDegree[x.degree] != nil {
+			y := byDegree[x.degree]
+			byDegree[x.degree] = nil
+			if h.less(y.v, x.v) {
+				x, y = y, x
+			}
+			if x.child == nil { // y becomes a child of x
+				x.child = y
+			} else {
+				spliceFib(x.child, y)
+			}
+			y.parent, y.mark = x, false
+			x.degree++
+		}
+		byDegree[x.degree] = x
+	}
+	h.min = nil
+	for _, x := range byDegree {
+		if x != nil {
+			h.addRoot(x)
+		}
+	}
+}
+
+// cut x from its parent to be a root
+func (h *FibonacciHeap) cut(x *fibNode) {
+	p := x.parent
+	if p.child == x {
+		if x.right == x {
+			p.child = nil
+		} else {
+			p.child = x.right
+		}
+	}
+	unlinkFib(x)
+	p.degree--
+	x.parent, x.mark = nil, false
+	spliceFib(h.min, x)
+}
+
+// cascadingCut cuts x if it has lost a child before, and goes up, otherwise marks x
+func (h *FibonacciHeap) cascadingCut(x *fibNode) {
+	for p := x.parent; p != nil; x, p = p, p.parent {
+		if !x.mark {
+			x.mark = true
+			return
+		}
+		h.cut(x)
+	}
+}
+
+// spliceFib joins the circular list of b into the one of a, after a
+func spliceFib(a *fibNode, b *fibNode) {
+	aRight, bLeft := a.right, b.left
+	a.right, b.left = b, a
+	bLeft.right, aRight.left = aRight, bLeft
+}
+
+// unlinkFib removes x from its circular list, x becomes a single node list
+func unlinkFib(x *fibNode) {
+	x.left.right, x.right.left = x.right, x.left
+	x.left, x.right = x, x
+}
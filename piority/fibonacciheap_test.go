@@ -0,0 +1,56 @@
+package piority
+
+import (
+	"testing"
+)
+
+// checkFib checks heap order, parent links and degrees of the list from x, returns the number of nodes
+func checkFib(t *testing.T, x *fibNode, parent *fibNode, less Less) int {
+	if x == nil {
+		return 0
+	}
+	n, cnt := 0, 0
+	for y := x; ; {
+		cnt++
+		if y.parent != parent || y.right.left != y {
+			t.Fatalf("node %v has broken links", y.v)
+		}
+		if parent != nil && less(y.v, parent.v) {
+			t.Fatalf("child %v is less than %v", y.v, parent.v)
+		}
+		n += 1 + checkFib(t, y.child, y, less)
+		if y = y.right; y == x {
+			break
+		}
+	}
+	if parent != nil && parent.degree != cnt {
+		t.Fatalf("node %v expected degree %d, got %d", parent.v, cnt, parent.degree)
+	}
+	return n
+}
+
+func TestFibonacciHeap_Merge(t *testing.T) {
+	a, b := NewFibonacciHeap(intLess), NewFibonacciHeap(intLess)
+	va, vb := randomInts(500, 1000), randomInts(700, 1000)
+	for _, v := range va {
+		a.Push(v)
+	}
+	for _, v := range vb {
+		b.Push(v)
+	}
+	a.Pop()
+	b.Pop() // consolidated
+	va, vb = sortedTail(va), sortedTail(vb)
+	a.Merge(&b)
+	if !b.Empty() {
+		t.Fatalf("expected other empty after merge")
+	}
+	if n := checkFib(t, a.min, nil, intLess); n != a.Size() {
+		t.Fatalf("expected %d nodes, got %d", a.Size(), n)
+	}
+	a.Pop()
+	if n := checkFib(t, a.min, nil, intLess); n != a.Size() {
+		t.Fatalf("expected %d nodes, got %d", a.Size(), n)
+	}
+	popAll(t, "FibonacciHeap", a.Pop, sortedTail(append(va, vb...)))
+}
@@ -1 +1,174 @@
 package piority
+
+var _ AddressableQueue = (*BinaryHeap)(nil)
+
+// BinaryHeap is a complete binary tree in a slice, every node is not less than its parent.
+// Push, Pop, Update, DecreaseKey and Remove are o(logn), Peek is o(1).
+type BinaryHeap struct {
+	nodes []*binaryNode
+	less  Less
+}
+
+type binaryNode struct {
+	v interface{}
+	i int // index in nodes, -1 if removed
+}
+
+func (x *binaryNode) Value() interface{} {
+	return x.v
+}
+
+// NewBinaryHeap returns an empty heap ordered by less, by ItemLess if nil
+func NewBinaryHeap(less Less) BinaryHeap {
+	if less == nil {
+		less = ItemLess
+	}
+	return BinaryHeap{less: less}
+}
+
+// NewBinaryHeapFrom builds a heap of vals by Floyd's heapify, o(n).
+// It returns no handles, use PushAll on an empty heap to address the elements later.
+func NewBinaryHeapFrom(vals []interface{}, less Less) BinaryHeap {
+	h := NewBinaryHeap(less)
+	h.PushAll(vals)
+	return h
+}
+
+// PushAll pushes vals by Floyd's heapify over the whole heap, o(n+len(vals)), and returns their
+// handles in the order of vals
+func (h *BinaryHeap) PushAll(vals []interface{}) []Handle {
+	handles := make([]Handle, len(vals))
+	for i, v := range vals {
+		x := &binaryNode{v: v, i: len(h.nodes)}
+		h.nodes = append(h.nodes, x)
+		handles[i] = x
+	}
+	for i := len(h.nodes)/2 - 1; i >= 0; i-- { // sift down from the last internal node
+		h.down(i)
+	}
+	return handles
+}
+
+func (h *BinaryHeap) Size() int {
+	return len(h.nodes)
+}
+
+func (h *BinaryHeap) Empty() bool {
+	return len(h.nodes) == 0
+}
+
+// Push v to the heap
+func (h *BinaryHeap) Push(v interface{}) {
+	h.Insert(v)
+}
+
+// Insert pushes v, and returns its handle
+func (h *BinaryHeap) Insert(v interface{}) Handle {
+	x := &binaryNode{v: v, i: len(h.nodes)}
+	h.nodes = append(h.nodes, x)
+	h.up(x.i)
+	return x
+}
+
+// Peek returns the least element, nil if empty
+func (h *BinaryHeap) Peek() interface{} {
+	if h.Empty() {
+		return nil
+	}
+	return h.nodes[0].v
+}
+
+// Pop removes and returns the least element, nil if empty
+func (h *BinaryHeap) Pop() interface{} {
+	if h.Empty() {
+		return nil
+	}
+	return h.removeAt(0)
+}
+
+// Update changes the element of x to v, which may go either way
+func (h *BinaryHeap) Update(x Handle, v interface{}) {
+	i := h.index(x)
+	h.nodes[i].v = v
+	if !h.up(i) {
+		h.down(i)
+	}
+}
+
+// DecreaseKey changes the element of x to v, which must not be greater than the old one, otherwise panic
+func (h *BinaryHeap) DecreaseKey(x Handle, v interface{}) {
+	i := h.index(x)
+	if h.less(h.nodes[i].v, v) {
+		panic("BinaryHeap.DecreaseKey: new value is greater")
+	}
+	h.nodes[i].v = v
+	h.up(i)
+}
+
+// Remove the element of x, and returns it
+func (h *BinaryHeap) Remove(x Handle) interface{} {
+	return h.removeAt(h.index(x))
+}
+
+// index returns the index of handle x, panics if it's removed or not of this heap
+func (h *BinaryHeap) index(x Handle) int {
+	nd, ok := x.(*binaryNode)
+	if !ok || nd.i < 0 || nd.i >= len(h.nodes) || h.nodes[nd.i] != nd {
+		panic("BinaryHeap: invalid handle")
+	}
+	return nd.i
+}
+
+// removeAt moves the last one to i, and sifts it up or down
+func (h *BinaryHeap) removeAt(i int) interface{} {
+	x := h.nodes[i]
+	last := len(h.nodes) - 1
+	h.swap(i, last)
+	h.nodes[last] = nil
+	h.nodes = h.nodes[:last]
+	if i < last && !h.up(i) {
+		h.down(i)
+	}
+	x.i = -1
+	return x.v
+}
+
+// up sifts node i up until its parent is not greater, returns true if it moved
+func (h *BinaryHeap) up(i int) bool {
+	moved := false
+	for i > 0 {
+		p := (i - 1) / 2
+		if !h.less(h.nodes[i].v, h.nodes[p].v) {
+			break
+		}
+		h.swap(i, p)
+		i = p
+		moved = true
+	}
+	return moved
+}
+
+// down sifts node i down until no child is less than it
+func (h *BinaryHeap) down(i int) {
+	n := len(h.nodes)
+	for {
+		c := 2*i + 1
+		if c >= n {
+			return
+		}
+		if c+1 < n && h.less(h.nodes[c+1].v, h.nodes[c].v) {
+			c++
+		}
+		if !h.less(h.nodes[c].v, h.nodes[i].v) {
+			return
+		}
+		h.swap(i, c)
+		i = c
+	}
+}
+
+func (h *BinaryHeap) swap(i int, j int) {
+	h.nodes[i], h.nodes[j] = h.nodes[j], h.nodes[i]
+	h.nodes[i].i = i
+	h.nodes[j].i = j
+}
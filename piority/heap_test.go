@@ -0,0 +1,171 @@
+package piority
+
+import (
+	"github.com/joexzh/dsa"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func checkBinaryHeap(t *testing.T, h *BinaryHeap) {
+	for i, x := range h.nodes {
+		if x.i != i {
+			t.Fatalf("node %d has index %d", i, x.i)
+		}
+		if i > 0 && h.less(x.v, h.nodes[(i-1)/2].v) {
+			t.Fatalf("node %d %v is less than its parent %v", i, x.v, h.nodes[(i-1)/2].v)
+		}
+	}
+}
+
+func randomInts(n int, max int) []int {
+	vals := make([]int, n)
+	for i := range vals {
+		vals[i] = rand.Intn(max)
+	}
+	return vals
+}
+
+func intLess(a interface{}, b interface{}) bool {
+	return a.(int) < b.(int)
+}
+
+// popAll pops the queue and checks the order equals the sorted vals
+func popAll(t *testing.T, name string, pop func() interface{}, vals []int) {
+	sorted := append([]int(nil), vals...)
+	sort.Ints(sorted)
+	for i, v := range sorted {
+		if got := pop(); got != v {
+			t.Fatalf("%s: pop %d expected %d, got %v", name, i, v, got)
+		}
+	}
+	if got := pop(); got != nil {
+		t.Fatalf("%s: expected nil popping empty, got %v", name, got)
+	}
+}
+
+func TestBinaryHeap_PushPop(t *testing.T) {
+	h := NewBinaryHeap(nil)
+	vals := randomInts(1000, 300)
+	for _, v := range vals {
+		h.Push(dsa.Int64(v))
+	}
+	checkBinaryHeap(t, &h)
+	if h.Size() != len(vals) {
+		t.Fatalf("expected size %d, got %d", len(vals), h.Size())
+	}
+	sort.Ints(vals)
+	for i, v := range vals {
+		if got := h.Peek(); got != dsa.Int64(v) {
+			t.Fatalf("peek %d expected %d, got %v", i, v, got)
+		}
+		if got := h.Pop(); got != dsa.Int64(v) {
+			t.Fatalf("pop %d expected %d, got %v", i, v, got)
+		}
+	}
+	if !h.Empty() || h.Pop() != nil || h.Peek() != nil {
+		t.Fatalf("expected empty")
+	}
+}
+
+func TestNewBinaryHeapFrom(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 10, 1000} {
+		vals := randomInts(n, 100)
+		ivals := make([]interface{}, n)
+		for i, v := range vals {
+			ivals[i] = v
+		}
+		h := NewBinaryHeapFrom(ivals, intLess)
+		checkBinaryHeap(t, &h)
+		popAll(t, "BinaryHeap", h.Pop, vals)
+	}
+}
+
+func TestBinaryHeap_PushAll(t *testing.T) {
+	h := NewBinaryHeap(intLess)
+	h.Push(50)
+	vals := randomInts(1000, 100)
+	ivals := make([]interface{}, len(vals))
+	for i, v := range vals {
+		ivals[i] = v
+	}
+	handles := h.PushAll(ivals)
+	checkBinaryHeap(t, &h)
+	for i, x := range handles {
+		if x.Value() != vals[i] {
+			t.Fatalf("handle %d expected %d, got %v", i, vals[i], x.Value())
+		}
+	}
+	for i := 0; i < len(handles); i += 2 { // the heapified elements are addressable
+		h.Remove(handles[i])
+		checkBinaryHeap(t, &h)
+	}
+	rest := []int{50}
+	for i := 1; i < len(vals); i += 2 {
+		rest = append(rest, vals[i])
+	}
+	popAll(t, "BinaryHeap", h.Pop, rest)
+}
+
+func TestBinaryHeap_Handle(t *testing.T) {
+	h := NewBinaryHeap(intLess)
+	handles := make([]Handle, 0)
+	live := make(map[Handle]bool)
+	for i := 0; i < 3000; i++ {
+		switch rand.Intn(5) {
+		case 0, 1:
+			x := h.Insert(rand.Intn(1000))
+			handles = append(handles, x)
+			live[x] = true
+		case 2:
+			if len(handles) > 0 {
+				x := handles[rand.Intn(len(handles))]
+				if live[x] {
+					h.DecreaseKey(x, x.Value().(int)-rand.Intn(100))
+				}
+			}
+		case 3:
+			if len(handles) > 0 {
+				x := handles[rand.Intn(len(handles))]
+				if live[x] {
+					h.Update(x, rand.Intn(1000))
+				}
+			}
+		default:
+			if len(handles) > 0 {
+				x := handles[rand.Intn(len(handles))]
+				if live[x] {
+					if got := h.Remove(x); got != x.Value() {
+						t.Fatalf("remove expected %v, got %v", x.Value(), got)
+					}
+					delete(live, x)
+				}
+			}
+		}
+	}
+	checkBinaryHeap(t, &h)
+	vals := make([]int, 0, len(live))
+	for x := range live {
+		vals = append(vals, x.Value().(int))
+	}
+	popAll(t, "BinaryHeap", h.Pop, vals)
+}
+
+func TestBinaryHeap_InvalidHandle(t *testing.T) {
+	h := NewBinaryHeap(intLess)
+	x := h.Insert(1)
+	h.Insert(2)
+	for _, f := range []func(){
+		func() { h.DecreaseKey(x, 5) },
+		func() { h.Remove(x); h.Remove(x) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
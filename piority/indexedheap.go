@@ -0,0 +1,158 @@
+package piority
+
+// IndexedHeap is a binary heap of priorities keyed by ids, an id is an int, a dsa.Item or any other
+// comparable value. Elements are found by ids instead of handles, so the callers only keep their own
+// ids, like vertices of a graph. Push, Pop, ChangePriority and Delete are o(logn), Contains, Priority,
+// Peek and PeekID are o(1).
+type IndexedHeap struct {
+	entries []indexedEntry
+	index   map[interface{}]int // id to index in entries
+	less    Less                // of priorities
+}
+
+type indexedEntry struct {
+	id interface{}
+	p  interface{}
+}
+
+// NewIndexedHeap returns an empty heap whose priorities are ordered by less, by ItemLess if nil
+func NewIndexedHeap(less Less) IndexedHeap {
+	if less == nil {
+		less = ItemLess
+	}
+	return IndexedHeap{index: make(map[interface{}]int), less: less}
+}
+
+func (h *IndexedHeap) Size() int {
+	return len(h.entries)
+}
+
+func (h *IndexedHeap) Empty() bool {
+	return len(h.entries) == 0
+}
+
+// Contains returns true if id is in the heap
+func (h *IndexedHeap) Contains(id interface{}) bool {
+	_, ok := h.index[id]
+	return ok
+}
+
+// Priority returns the priority of id, false if not found
+func (h *IndexedHeap) Priority(id interface{}) (interface{}, bool) {
+	i, ok := h.index[id]
+	if !ok {
+		return nil, false
+	}
+	return h.entries[i].p, true
+}
+
+// Push id of priority p. If id already exists, changes its priority and returns false.
+func (h *IndexedHeap) Push(id interface{}, p interface{}) bool {
+	if h.ChangePriority(id, p) {
+		return false
+	}
+	i := len(h.entries)
+	h.entries = append(h.entries, indexedEntry{id, p})
+	h.index[id] = i
+	h.up(i)
+	return true
+}
+
+// ChangePriority of id to p, which may go either way, returns false if id is not found
+func (h *IndexedHeap) ChangePriority(id interface{}, p interface{}) bool {
+	i, ok := h.index[id]
+	if !ok {
+		return false
+	}
+	h.entries[i].p = p
+	if !h.up(i) {
+		h.down(i)
+	}
+	return true
+}
+
+// Delete id, returns false if not found
+func (h *IndexedHeap) Delete(id interface{}) bool {
+	i, ok := h.index[id]
+	if !ok {
+		return false
+	}
+	h.removeAt(i)
+	return true
+}
+
+// PeekID returns the id of the least priority, nil if empty
+func (h *IndexedHeap) PeekID() interface{} {
+	id, _ := h.Peek()
+	return id
+}
+
+// Peek returns the id and the least priority, nils if empty
+func (h *IndexedHeap) Peek() (interface{}, interface{}) {
+	if h.Empty() {
+		return nil, nil
+	}
+	return h.entries[0].id, h.entries[0].p
+}
+
+// Pop removes the least priority, and returns it with its id, nils if empty
+func (h *IndexedHeap) Pop() (interface{}, interface{}) {
+	if h.Empty() {
+		return nil, nil
+	}
+	e := h.entries[0]
+	h.removeAt(0)
+	return e.id, e.p
+}
+
+// removeAt moves the last one to i, and sifts it up or down
+func (h *IndexedHeap) removeAt(i int) {
+	last := len(h.entries) - 1
+	h.swap(i, last)
+	delete(h.index, h.entries[last].id)
+	h.entries[last] = indexedEntry{}
+	h.entries = h.entries[:last]
+	if i < last && !h.up(i) {
+		h.down(i)
+	}
+}
+
+// up sifts entry i up until its parent is not greater, returns true if it moved
+func (h *IndexedHeap) up(i int) bool {
+	moved := false
+	for i > 0 {
+		p := (i - 1) / 2
+		if !h.less(h.entries[i].p, h.entries[p].p) {
+			break
+		}
+		h.swap(i, p)
+		i = p
+		moved = true
+	}
+	return moved
+}
+
+// down sifts entry i down until no child is less than it
+func (h *IndexedHeap) down(i int) {
+	n := len(h.entries)
+	for {
+		c := 2*i + 1
+		if c >= n {
+			return
+		}
+		if c+1 < n && h.less(h.entries[c+1].p, h.entries[c].p) {
+			c++
+		}
+		if !h.less(h.entries[c].p, h.entries[i].p) {
+			return
+		}
+		h.swap(i, c)
+		i = c
+	}
+}
+
+func (h *IndexedHeap) swap(i int, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.index[h.entries[i].id] = i
+	h.index[h.entries[j].id] = j
+}
@@ -0,0 +1,185 @@
+package piority
+
+import (
+	"fmt"
+	"github.com/joexzh/dsa"
+	"math/rand"
+	"testing"
+)
+
+func checkIndexedHeap(t *testing.T, h *IndexedHeap) {
+	if len(h.index) != len(h.entries) {
+		t.Fatalf("index has %d ids, entries has %d", len(h.index), len(h.entries))
+	}
+	for i, e := range h.entries {
+		if h.index[e.id] != i {
+			t.Fatalf("entry %d of id %v has index %d", i, e.id, h.index[e.id])
+		}
+		if i > 0 && h.less(e.p, h.entries[(i-1)/2].p) {
+			t.Fatalf("entry %d %v is less than its parent %v", i, e.p, h.entries[(i-1)/2].p)
+		}
+	}
+}
+
+func TestIndexedHeap(t *testing.T) {
+	ids := map[string]func(i int) interface{}{
+		"int":    func(i int) interface{} { return i },
+		"String": func(i int) interface{} { return dsa.String(fmt.Sprint(i)) },
+	}
+	for name, id := range ids {
+		h := NewIndexedHeap(intLess)
+		m := make(map[interface{}]int)
+		for i := 0; i < 5000; i++ {
+			k, p := id(rand.Intn(500)), rand.Intn(1000)
+			switch rand.Intn(4) {
+			case 0:
+				_, exist := m[k]
+				if ok := h.Push(k, p); ok == exist {
+					t.Fatalf("%s: push %v expected %v, got %v", name, k, !exist, ok)
+				}
+				m[k] = p
+			case 1:
+				_, exist := m[k]
+				if ok := h.ChangePriority(k, p); ok != exist {
+					t.Fatalf("%s: change %v expected %v, got %v", name, k, exist, ok)
+				}
+				if exist {
+					m[k] = p
+				}
+			case 2:
+				_, exist := m[k]
+				if ok := h.Delete(k); ok != exist {
+					t.Fatalf("%s: delete %v expected %v, got %v", name, k, exist, ok)
+				}
+				delete(m, k)
+			default:
+				if h.Empty() {
+					break
+				}
+				k, p := h.Pop()
+				for _, v := range m {
+					if v < p.(int) {
+						t.Fatalf("%s: pop %v of %v, but %v is less", name, k, p, v)
+					}
+				}
+				if m[k] != p {
+					t.Fatalf("%s: pop %v expected priority %v, got %v", name, k, m[k], p)
+				}
+				delete(m, k)
+			}
+			checkIndexedHeap(t, &h)
+			if h.Size() != len(m) {
+				t.Fatalf("%s: expected size %d, got %d", name, len(m), h.Size())
+			}
+		}
+		for k, p := range m {
+			if !h.Contains(k) {
+				t.Fatalf("%s: expected %v contained", name, k)
+			}
+			if got, _ := h.Priority(k); got != p {
+				t.Fatalf("%s: %v expected priority %v, got %v", name, k, p, got)
+			}
+		}
+		if h.Contains(id(-1)) {
+			t.Fatalf("%s: expected %v not contained", name, id(-1))
+		}
+	}
+}
+
+func TestIndexedHeap_Empty(t *testing.T) {
+	h := NewIndexedHeap(nil)
+	if id := h.PeekID(); id != nil {
+		t.Fatalf("expected nil peeking empty, got %v", id)
+	}
+	if id, p := h.Pop(); id != nil || p != nil {
+		t.Fatalf("expected nils popping empty, got %v %v", id, p)
+	}
+	h.Push(dsa.String("b"), dsa.Int64(2))
+	h.Push(dsa.String("a"), dsa.Int64(1))
+	if id := h.PeekID(); id != dsa.String("a") {
+		t.Fatalf("expected a, got %v", id)
+	}
+}
+
+// prim returns the total weight of the minimum spanning tree of connected adj, by an IndexedHeap of
+// vertices, the graph is taken as undirected
+func prim(adj [][]edge) int {
+	undirected := make([][]edge, len(adj))
+	for u, es := range adj {
+		for _, e := range es {
+			undirected[u] = append(undirected[u], e)
+			undirected[e.to] = append(undirected[e.to], edge{u, e.w})
+		}
+	}
+	done := make([]bool, len(adj))
+	h := NewIndexedHeap(intLess)
+	h.Push(0, 0)
+	total := 0
+	for !h.Empty() {
+		id, w := h.Pop()
+		u := id.(int)
+		done[u] = true
+		total += w.(int)
+		for _, e := range undirected[u] {
+			if done[e.to] {
+				continue
+			}
+			if p, ok := h.Priority(e.to); !ok || e.w < p.(int) {
+				h.Push(e.to, e.w)
+			}
+		}
+	}
+	return total
+}
+
+// kruskal returns the total weight of the minimum spanning tree of connected adj, by union-find
+func kruskal(adj [][]edge) int {
+	type wedge struct{ u, v, w int }
+	es := make([]wedge, 0)
+	for u := range adj {
+		for _, e := range adj[u] {
+			es = append(es, wedge{u, e.to, e.w})
+		}
+	}
+	h := NewBinaryHeap(func(a interface{}, b interface{}) bool {
+		return a.(wedge).w < b.(wedge).w
+	})
+	for _, e := range es {
+		h.Push(e)
+	}
+	parent := make([]int, len(adj))
+	for v := range parent {
+		parent[v] = v
+	}
+	var find func(v int) int
+	find = func(v int) int {
+		if parent[v] != v {
+			parent[v] = find(parent[v])
+		}
+		return parent[v]
+	}
+	total := 0
+	for !h.Empty() {
+		e := h.Pop().(wedge)
+		if ru, rv := find(e.u), find(e.v); ru != rv {
+			parent[ru] = rv
+			total += e.w
+		}
+	}
+	return total
+}
+
+func TestIndexedHeap_Prim(t *testing.T) {
+	adj := randomGraph(2000, 5)
+	if expected, got := kruskal(adj), prim(adj); expected != got {
+		t.Fatalf("expected MST weight %d, got %d", expected, got)
+	}
+}
+
+func BenchmarkIndexedHeap_Prim(b *testing.B) {
+	adj := randomGraph(1<<14, 8)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prim(adj)
+	}
+}
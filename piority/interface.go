@@ -0,0 +1,33 @@
+package piority
+
+import "github.com/joexzh/dsa"
+
+// PriorityQueue pops the least element first, by the order of the queue
+type PriorityQueue interface {
+	Push(v interface{})
+	Pop() interface{}  // nil if empty
+	Peek() interface{} // nil if empty
+	Size() int
+	Empty() bool
+}
+
+// Less reports whether a goes before b, the least element is popped first
+type Less func(a interface{}, b interface{}) bool
+
+// ItemLess compares elements as dsa.Item, used when no Less is given
+func ItemLess(a interface{}, b interface{}) bool {
+	return a.(dsa.Item).Less(b.(dsa.Item))
+}
+
+// Handle refers to an element in a heap, to update or remove it later
+type Handle interface {
+	Value() interface{}
+}
+
+// AddressableQueue is a PriorityQueue whose elements can be reprioritized or removed by handles
+type AddressableQueue interface {
+	PriorityQueue
+	Insert(v interface{}) Handle
+	DecreaseKey(x Handle, v interface{}) // v must not be greater than the old one
+	Remove(x Handle) interface{}
+}
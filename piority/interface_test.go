@@ -0,0 +1,317 @@
+package piority
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// testPriorityQueue pushes and pops vals in turns, and checks against a sorted slice
+func testPriorityQueue(t *testing.T, name string, pq PriorityQueue) {
+	vals := randomInts(2000, 500)
+	pushed := make([]int, 0)
+	for i, v := range vals {
+		pq.Push(v)
+		pushed = append(pushed, v)
+		if i%3 == 2 { // pop the least one
+			min := 0
+			for j := range pushed {
+				if pushed[j] < pushed[min] {
+					min = j
+				}
+			}
+			if got := pq.Peek(); got != pushed[min] {
+				t.Fatalf("%s: peek expected %d, got %v", name, pushed[min], got)
+			}
+			if got := pq.Pop(); got != pushed[min] {
+				t.Fatalf("%s: pop expected %d, got %v", name, pushed[min], got)
+			}
+			pushed = append(pushed[:min], pushed[min+1:]...)
+		}
+	}
+	if pq.Size() != len(pushed) {
+		t.Fatalf("%s: expected size %d, got %d", name, len(pushed), pq.Size())
+	}
+	popAll(t, name, pq.Pop, pushed)
+	if !pq.Empty() || pq.Peek() != nil {
+		t.Fatalf("%s: expected empty", name)
+	}
+}
+
+func TestPriorityQueue(t *testing.T) {
+	bh, lh := NewBinaryHeap(intLess), NewLeftistHeap(intLess)
+	ph, fh, bn := NewPairingHeap(intLess), NewFibonacciHeap(intLess), NewBinomialHeap(intLess)
+	for name, pq := range map[string]PriorityQueue{
+		"BinaryHeap": &bh, "LeftistHeap": &lh, "PairingHeap": &ph, "FibonacciHeap": &fh, "BinomialHeap": &bn,
+	} {
+		testPriorityQueue(t, name, pq)
+	}
+}
+
+func newAddressableQueues() map[string]AddressableQueue {
+	bh, ph := NewBinaryHeap(intLess), NewPairingHeap(intLess)
+	fh, bn := NewFibonacciHeap(intLess), NewBinomialHeap(intLess)
+	return map[string]AddressableQueue{"BinaryHeap": &bh, "PairingHeap": &ph, "FibonacciHeap": &fh, "BinomialHeap": &bn}
+}
+
+// testAddressableQueue does random operations by handles, and checks against the live ones.
+// Values are unique by i in the low digits, so the popped handle is known.
+func testAddressableQueue(t *testing.T, name string, q AddressableQueue) {
+	handles := make([]Handle, 0)
+	live := make(map[Handle]bool)
+	pick := func() Handle {
+		for len(handles) > 0 {
+			i := rand.Intn(len(handles))
+			if x := handles[i]; live[x] {
+				return x
+			}
+			handles[i] = handles[len(handles)-1]
+			handles = handles[:len(handles)-1]
+		}
+		return nil
+	}
+	for i := 0; i < 5000; i++ {
+		switch op := rand.Intn(10); {
+		case op < 4:
+			x := q.Insert(rand.Intn(10000)*10000 + i)
+			handles = append(handles, x)
+			live[x] = true
+		case op < 7:
+			if x := pick(); x != nil {
+				q.DecreaseKey(x, x.Value().(int)-rand.Intn(1000)*10000)
+			}
+		case op < 9:
+			if x := pick(); x != nil {
+				if got := q.Remove(x); got != x.Value() {
+					t.Fatalf("%s: remove expected %v, got %v", name, x.Value(), got)
+				}
+				delete(live, x)
+			}
+		default:
+			if len(live) == 0 {
+				continue
+			}
+			min, first := 0, true
+			for x := range live {
+				if v := x.Value().(int); first || v < min {
+					min, first = v, false
+				}
+			}
+			if got := q.Pop(); got != min {
+				t.Fatalf("%s: pop expected %d, got %v", name, min, got)
+			}
+			for x := range live {
+				if x.Value() == min {
+					delete(live, x)
+				}
+			}
+		}
+		if q.Size() != len(live) {
+			t.Fatalf("%s: expected size %d, got %d", name, len(live), q.Size())
+		}
+	}
+	vals := make([]int, 0, len(live))
+	for x := range live {
+		vals = append(vals, x.Value().(int))
+	}
+	popAll(t, name, q.Pop, vals)
+}
+
+func TestAddressableQueue(t *testing.T) {
+	for name, q := range newAddressableQueues() {
+		testAddressableQueue(t, name, q)
+	}
+}
+
+func TestAddressableQueue_InvalidHandle(t *testing.T) {
+	others := newAddressableQueues()
+	for name, q := range newAddressableQueues() {
+		x := q.Insert(1)
+		q.Insert(2)
+		y := others[name].Insert(0) // of another heap
+		for _, f := range []func(){
+			func() { q.DecreaseKey(x, 5) },
+			func() { q.DecreaseKey(y, 0) },
+			func() { q.Remove(y) },
+			func() { q.Remove(x); q.Remove(x) },
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Fatalf("%s: expected panic", name)
+					}
+				}()
+				f()
+			}()
+		}
+		if q.Size() != 1 || others[name].Size() != 1 || others[name].Peek() != 0 {
+			t.Fatalf("%s: expected both heaps unchanged by the invalid handles", name)
+		}
+	}
+}
+
+// TestAddressableQueue_MergeHandle checks handles of the merged heap belong to h after Merge
+func TestAddressableQueue_MergeHandle(t *testing.T) {
+	fa, fb := NewFibonacciHeap(intLess), NewFibonacciHeap(intLess)
+	pa, pb := NewPairingHeap(intLess), NewPairingHeap(intLess)
+	ba, bb := NewBinomialHeap(intLess), NewBinomialHeap(intLess)
+	for name, c := range map[string]struct {
+		h, other AddressableQueue
+		merge    func()
+	}{
+		"FibonacciHeap": {&fa, &fb, func() { fa.Merge(&fb) }},
+		"PairingHeap":   {&pa, &pb, func() { pa.Merge(&pb) }},
+		"BinomialHeap":  {&ba, &bb, func() { ba.Merge(&bb) }},
+	} {
+		c.h.Insert(5)
+		x := c.other.Insert(7)
+		c.merge()
+		c.h.DecreaseKey(x, 1)
+		if got := c.h.Pop(); got != 1 {
+			t.Fatalf("%s: expected 1 of the merged handle, got %v", name, got)
+		}
+		y := c.other.Insert(3) // other is still usable with its own handles
+		if got := c.other.Remove(y); got != 3 {
+			t.Fatalf("%s: expected 3 removed, got %v", name, got)
+		}
+	}
+}
+
+// sortedTail returns vals without the least one, in ascending order
+func sortedTail(vals []int) []int {
+	s := append([]int(nil), vals...)
+	sort.Ints(s)
+	return s[1:]
+}
+
+type edge struct {
+	to, w int
+}
+
+type vertexDist struct {
+	id, d int
+}
+
+func distLess(a interface{}, b interface{}) bool {
+	return a.(vertexDist).d < b.(vertexDist).d
+}
+
+// randomGraph returns a connected graph of n vertices and about n*degree edges
+func randomGraph(n int, degree int) [][]edge {
+	r := rand.New(rand.NewSource(1))
+	adj := make([][]edge, n)
+	for v := 1; v < n; v++ { // a random spanning tree first
+		u := r.Intn(v)
+		adj[u] = append(adj[u], edge{v, r.Intn(1000) + 1})
+	}
+	for i := 0; i < n*(degree-1); i++ {
+		u, v := r.Intn(n), r.Intn(n)
+		adj[u] = append(adj[u], edge{v, r.Intn(1000) + 1})
+	}
+	return adj
+}
+
+// dijkstra returns distances from vertex 0, by a queue of all vertices and DecreaseKey
+func dijkstra(adj [][]edge, q AddressableQueue) []int {
+	const inf = int(^uint(0) >> 1)
+	dist := make([]int, len(adj))
+	handles := make([]Handle, len(adj))
+	for v := range adj {
+		dist[v] = inf
+	}
+	dist[0] = 0
+	handles[0] = q.Insert(vertexDist{0, 0})
+	for !q.Empty() {
+		u := q.Pop().(vertexDist)
+		handles[u.id] = nil
+		for _, e := range adj[u.id] {
+			if d := u.d + e.w; d < dist[e.to] {
+				if dist[e.to] == inf {
+					handles[e.to] = q.Insert(vertexDist{e.to, d})
+				} else if handles[e.to] != nil {
+					q.DecreaseKey(handles[e.to], vertexDist{e.to, d})
+				}
+				dist[e.to] = d
+			}
+		}
+	}
+	return dist
+}
+
+// dijkstraScan returns distances from vertex 0 by scanning for the nearest unvisited vertex, o(V^2),
+// the oracle of the heaps
+func dijkstraScan(adj [][]edge) []int {
+	const inf = int(^uint(0) >> 1)
+	dist := make([]int, len(adj))
+	done := make([]bool, len(adj))
+	for v := range adj {
+		dist[v] = inf
+	}
+	dist[0] = 0
+	for {
+		u := -1
+		for v := range adj {
+			if !done[v] && dist[v] != inf && (u < 0 || dist[v] < dist[u]) {
+				u = v
+			}
+		}
+		if u < 0 {
+			return dist
+		}
+		done[u] = true
+		for _, e := range adj[u] {
+			if d := dist[u] + e.w; d < dist[e.to] {
+				dist[e.to] = d
+			}
+		}
+	}
+}
+
+func TestDijkstra(t *testing.T) {
+	adj := randomGraph(2000, 5)
+	expected := dijkstraScan(adj)
+	for _, name := range []string{"BinaryHeap", "PairingHeap", "FibonacciHeap", "BinomialHeap"} {
+		dist := dijkstra(adj, newDistQueue(name))
+		for v := range dist {
+			if dist[v] != expected[v] {
+				t.Fatalf("%s: vertex %d expected distance %d, got %d", name, v, expected[v], dist[v])
+			}
+		}
+	}
+}
+
+func newDistQueue(name string) AddressableQueue {
+	switch name {
+	case "BinaryHeap":
+		h := NewBinaryHeap(distLess)
+		return &h
+	case "PairingHeap":
+		h := NewPairingHeap(distLess)
+		return &h
+	case "FibonacciHeap":
+		h := NewFibonacciHeap(distLess)
+		return &h
+	default:
+		h := NewBinomialHeap(distLess)
+		return &h
+	}
+}
+
+func benchmarkDijkstra(b *testing.B, name string, degree int) {
+	adj := randomGraph(1<<14, degree)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dijkstra(adj, newDistQueue(name))
+	}
+}
+
+func BenchmarkBinaryHeap_Dijkstra(b *testing.B)    { benchmarkDijkstra(b, "BinaryHeap", 8) }
+func BenchmarkPairingHeap_Dijkstra(b *testing.B)   { benchmarkDijkstra(b, "PairingHeap", 8) }
+func BenchmarkFibonacciHeap_Dijkstra(b *testing.B) { benchmarkDijkstra(b, "FibonacciHeap", 8) }
+func BenchmarkBinomialHeap_Dijkstra(b *testing.B)  { benchmarkDijkstra(b, "BinomialHeap", 8) }
+
+// dense graphs have more DecreaseKey per Pop
+func BenchmarkBinaryHeap_DijkstraDense(b *testing.B)    { benchmarkDijkstra(b, "BinaryHeap", 64) }
+func BenchmarkPairingHeap_DijkstraDense(b *testing.B)   { benchmarkDijkstra(b, "PairingHeap", 64) }
+func BenchmarkFibonacciHeap_DijkstraDense(b *testing.B) { benchmarkDijkstra(b, "FibonacciHeap", 64) }
+func BenchmarkBinomialHeap_DijkstraDense(b *testing.B)  { benchmarkDijkstra(b, "BinomialHeap", 64) }
@@ -1 +1,175 @@
 package piority
+
+var _ PriorityQueue = (*LeftistHeap)(nil)
+
+// LeftistHeap is a binary tree with heap order, where the null path length (npl) of every left child
+// is not less than the right one, so the right spine is o(logn) long.
+// Merge walks along the right spines of both, Push and Pop are merges, all o(logn).
+type LeftistHeap struct {
+	root *leftistNode
+	size int
+	less Less
+}
+
+type leftistNode struct {
+	v   interface{}
+	npl int // length of the shortest path to a nil child, 1 for a node with a nil child
+	lc  *leftistNode
+	rc  *leftistNode
+}
+
+// NewLeftistHeap returns an empty heap ordered by less, by ItemLess if nil
+func NewLeftistHeap(less Less) LeftistHeap {
+	if less == nil {
+		less = ItemLess
+	}
+	return LeftistHeap{less: less}
+}
+
+func (h *LeftistHeap) Size() int {
+	return h.size
+}
+
+func (h *LeftistHeap) Empty() bool {
+	return h.size == 0
+}
+
+// Push v to the heap
+func (h *LeftistHeap) Push(v interface{}) {
+	h.root = leftistMerge(h.root, &leftistNode{v: v, npl: 1}, h.less)
+	h.size++
+}
+
+// Peek returns the least element, nil if empty
+func (h *LeftistHeap) Peek() interface{} {
+	if h.root == nil {
+		return nil
+	}
+	return h.root.v
+}
+
+// Pop removes and returns the least element, nil if empty
+func (h *LeftistHeap) Pop() interface{} {
+	if h.root == nil {
+		return nil
+	}
+	x := h.root
+	h.root = leftistMerge(x.lc, x.rc, h.less)
+	h.size--
+	return x.v
+}
+
+// Merge moves all elements of other into h, other becomes empty. Both must be in the same order.
+func (h *LeftistHeap) Merge(other *LeftistHeap) {
+	if h == other {
+		return
+	}
+	h.root = leftistMerge(h.root, other.root, h.less)
+	h.size += other.size
+	other.root, other.size = nil, 0
+}
+
+// PersistentLeftistHeap is an immutable LeftistHeap. Push, Pop and Merge return a new version,
+// copying the nodes along the right spines and sharing the others, o(logn) time and space.
+type PersistentLeftistHeap struct {
+	root *leftistNode
+	size int
+	less Less
+}
+
+// NewPersistentLeftistHeap returns an empty heap ordered by less, by ItemLess if nil
+func NewPersistentLeftistHeap(less Less) PersistentLeftistHeap {
+	if less == nil {
+		less = ItemLess
+	}
+	return PersistentLeftistHeap{less: less}
+}
+
+func (h PersistentLeftistHeap) Size() int {
+	return h.size
+}
+
+func (h PersistentLeftistHeap) Empty() bool {
+	return h.size == 0
+}
+
+// Push returns a new version with v
+func (h PersistentLeftistHeap) Push(v interface{}) PersistentLeftistHeap {
+	h.root = leftistMergeCopy(h.root, &leftistNode{v: v, npl: 1}, h.less)
+	h.size++
+	return h
+}
+
+// Peek returns the least element, nil if empty
+func (h PersistentLeftistHeap) Peek() interface{} {
+	if h.root == nil {
+		return nil
+	}
+	return h.root.v
+}
+
+// Pop returns the least element and a new version without it, nil and h itself if empty
+func (h PersistentLeftistHeap) Pop() (interface{}, PersistentLeftistHeap) {
+	if h.root == nil {
+		return nil, h
+	}
+	x := h.root
+	h.root = leftistMergeCopy(x.lc, x.rc, h.less)
+	h.size--
+	return x.v, h
+}
+
+// Merge returns a new version with elements of both h and other, in the order of h
+func (h PersistentLeftistHeap) Merge(other PersistentLeftistHeap) PersistentLeftistHeap {
+	h.root = leftistMergeCopy(h.root, other.root, h.less)
+	h.size += other.size
+	return h
+}
+
+// leftistMerge merges a and b in place, returns the new root
+func leftistMerge(a *leftistNode, b *leftistNode, less Less) *leftistNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if less(b.v, a.v) {
+		a, b = b, a
+	}
+	a.rc = leftistMerge(a.rc, b, less)
+	a.fix()
+	return a
+}
+
+// leftistMergeCopy merges a and b without modifying them, the new root of each level is a copy
+func leftistMergeCopy(a *leftistNode, b *leftistNode, less Less) *leftistNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if less(b.v, a.v) {
+		a, b = b, a
+	}
+	n := *a
+	n.rc = leftistMergeCopy(a.rc, b, less)
+	n.fix()
+	return &n
+}
+
+// fix swaps the children if the right one has longer npl, and updates npl
+func (x *leftistNode) fix() {
+	if npl(x.lc) < npl(x.rc) {
+		x.lc, x.rc = x.rc, x.lc
+	}
+	x.npl = npl(x.rc) + 1
+}
+
+func npl(x *leftistNode) int {
+	if x == nil {
+		return 0
+	}
+	return x.npl
+}
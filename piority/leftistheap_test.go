@@ -0,0 +1,84 @@
+package piority
+
+import (
+	"testing"
+)
+
+// checkLeftist checks heap order, npl and the leftist property, returns the number of nodes
+func checkLeftist(t *testing.T, x *leftistNode, less Less) int {
+	if x == nil {
+		return 0
+	}
+	for _, c := range []*leftistNode{x.lc, x.rc} {
+		if c != nil && less(c.v, x.v) {
+			t.Fatalf("child %v is less than %v", c.v, x.v)
+		}
+	}
+	if npl(x.lc) < npl(x.rc) {
+		t.Fatalf("node %v has npl of left %d less than right %d", x.v, npl(x.lc), npl(x.rc))
+	}
+	if x.npl != npl(x.rc)+1 {
+		t.Fatalf("node %v expected npl %d, got %d", x.v, npl(x.rc)+1, x.npl)
+	}
+	return 1 + checkLeftist(t, x.lc, less) + checkLeftist(t, x.rc, less)
+}
+
+func TestLeftistHeap_Merge(t *testing.T) {
+	a, b := NewLeftistHeap(intLess), NewLeftistHeap(intLess)
+	va, vb := randomInts(500, 1000), randomInts(700, 1000)
+	for _, v := range va {
+		a.Push(v)
+	}
+	for _, v := range vb {
+		b.Push(v)
+	}
+	a.Merge(&b)
+	if !b.Empty() || b.root != nil {
+		t.Fatalf("expected other empty after merge")
+	}
+	if n := checkLeftist(t, a.root, intLess); n != a.Size() || n != len(va)+len(vb) {
+		t.Fatalf("expected size %d, got %d nodes, size %d", len(va)+len(vb), n, a.Size())
+	}
+	a.Merge(&a)
+	popAll(t, "LeftistHeap", a.Pop, append(va, vb...))
+}
+
+func TestPersistentLeftistHeap(t *testing.T) {
+	h := NewPersistentLeftistHeap(intLess)
+	vals := randomInts(1000, 300)
+	versions := make([]PersistentLeftistHeap, 0)
+	for _, v := range vals {
+		versions = append(versions, h)
+		h = h.Push(v)
+	}
+	for i, ver := range versions { // each version has the first i values
+		if i%97 != 0 {
+			continue
+		}
+		if n := checkLeftist(t, ver.root, intLess); n != i || ver.Size() != i {
+			t.Fatalf("version %d has %d nodes, size %d", i, n, ver.Size())
+		}
+		pop := func() interface{} {
+			var v interface{}
+			v, ver = ver.Pop()
+			return v
+		}
+		popAll(t, "PersistentLeftistHeap", pop, vals[:i])
+	}
+
+	other := NewPersistentLeftistHeap(intLess)
+	for _, v := range vals[:100] {
+		other = other.Push(v)
+	}
+	merged := h.Merge(other)
+	checkLeftist(t, merged.root, intLess)
+	pop := func() interface{} {
+		var v interface{}
+		v, merged = merged.Pop()
+		return v
+	}
+	popAll(t, "PersistentLeftistHeap", pop, append(append([]int(nil), vals...), vals[:100]...))
+	if h.Size() != len(vals) || checkLeftist(t, h.root, intLess) != len(vals) {
+		t.Fatalf("expected the merged ones unchanged")
+	}
+}
@@ -0,0 +1,177 @@
+package piority
+
+import (
+	"math/bits"
+)
+
+// MinMaxHeap is a double-ended priority queue in a complete binary tree, whose levels are min and max
+// levels in turn from the root. A node on a min level is not greater than all its descendants,
+// and one on a max level is not less than them, so the min is the root, and the max is one of
+// its children. Push, PopMin and PopMax are o(logn).
+type MinMaxHeap struct {
+	vals []interface{}
+	less Less
+}
+
+// NewMinMaxHeap returns an empty heap ordered by less, by ItemLess if nil
+func NewMinMaxHeap(less Less) MinMaxHeap {
+	if less == nil {
+		less = ItemLess
+	}
+	return MinMaxHeap{less: less}
+}
+
+// NewMinMaxHeapFrom builds a heap of vals by trickling down from the last internal node, o(n)
+func NewMinMaxHeapFrom(vals []interface{}, less Less) MinMaxHeap {
+	h := NewMinMaxHeap(less)
+	h.vals = append([]interface{}(nil), vals...)
+	for i := len(h.vals)/2 - 1; i >= 0; i-- {
+		h.down(i)
+	}
+	return h
+}
+
+func (h *MinMaxHeap) Size() int {
+	return len(h.vals)
+}
+
+func (h *MinMaxHeap) Empty() bool {
+	return len(h.vals) == 0
+}
+
+// Push v to the heap
+func (h *MinMaxHeap) Push(v interface{}) {
+	h.vals = append(h.vals, v)
+	h.up(len(h.vals) - 1)
+}
+
+// PeekMin returns the least element, nil if empty
+func (h *MinMaxHeap) PeekMin() interface{} {
+	if h.Empty() {
+		return nil
+	}
+	return h.vals[0]
+}
+
+// PeekMax returns the greatest element, nil if empty
+func (h *MinMaxHeap) PeekMax() interface{} {
+	if h.Empty() {
+		return nil
+	}
+	return h.vals[h.maxIndex()]
+}
+
+// PopMin removes and returns the least element, nil if empty
+func (h *MinMaxHeap) PopMin() interface{} {
+	if h.Empty() {
+		return nil
+	}
+	return h.removeAt(0)
+}
+
+// PopMax removes and returns the greatest element, nil if empty
+func (h *MinMaxHeap) PopMax() interface{} {
+	if h.Empty() {
+		return nil
+	}
+	return h.removeAt(h.maxIndex())
+}
+
+// maxIndex is the root if it's the only one, or the greater child of root
+func (h *MinMaxHeap) maxIndex() int {
+	switch len(h.vals) {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	}
+	if h.less(h.vals[1], h.vals[2]) {
+		return 2
+	}
+	return 1
+}
+
+// removeAt moves the last one to i, which is the root or a child of root, and trickles it down
+func (h *MinMaxHeap) removeAt(i int) interface{} {
+	v := h.vals[i]
+	last := len(h.vals) - 1
+	h.vals[i] = h.vals[last]
+	h.vals[last] = nil
+	h.vals = h.vals[:last]
+	if i < last {
+		h.down(i)
+	}
+	return v
+}
+
+// up bubbles node i up, among the min or max levels depending on which side of its parent it's on
+func (h *MinMaxHeap) up(i int) {
+	if i == 0 {
+		return
+	}
+	p := (i - 1) / 2
+	if isMinLevel(i) {
+		if h.less(h.vals[p], h.vals[i]) { // greater than a max node, goes along max levels
+			h.vals[i], h.vals[p] = h.vals[p], h.vals[i]
+			h.upAlong(p, false)
+		} else {
+			h.upAlong(i, true)
+		}
+	} else {
+		if h.less(h.vals[i], h.vals[p]) {
+			h.vals[i], h.vals[p] = h.vals[p], h.vals[i]
+			h.upAlong(p, true)
+		} else {
+			h.upAlong(i, false)
+		}
+	}
+}
+
+// upAlong bubbles node i up by its grandparents, on min levels or max levels
+func (h *MinMaxHeap) upAlong(i int, min bool) {
+	for i > 2 {
+		g := ((i-1)/2 - 1) / 2
+		if !h.before(i, g, min) {
+			return
+		}
+		h.vals[i], h.vals[g] = h.vals[g], h.vals[i]
+		i = g
+	}
+}
+
+// down trickles node i down, among its children and grandchildren
+func (h *MinMaxHeap) down(i int) {
+	min := isMinLevel(i)
+	n := len(h.vals)
+	for {
+		m := -1 // the least (or greatest on max level) of children and grandchildren
+		for _, c := range [...]int{2*i + 1, 2*i + 2, 4*i + 3, 4*i + 4, 4*i + 5, 4*i + 6} {
+			if c < n && (m < 0 || h.before(c, m, min)) {
+				m = c
+			}
+		}
+		if m < 0 || !h.before(m, i, min) {
+			return
+		}
+		h.vals[i], h.vals[m] = h.vals[m], h.vals[i]
+		if m <= 2*i+2 { // a child, which has no descendants
+			return
+		}
+		if p := (m - 1) / 2; h.before(p, m, min) { // the one moved down is on the wrong side of p
+			h.vals[m], h.vals[p] = h.vals[p], h.vals[m]
+		}
+		i = m
+	}
+}
+
+// before returns true if node i goes before node j, on min levels it's less, on max levels greater
+func (h *MinMaxHeap) before(i int, j int, min bool) bool {
+	if min {
+		return h.less(h.vals[i], h.vals[j])
+	}
+	return h.less(h.vals[j], h.vals[i])
+}
+
+func isMinLevel(i int) bool {
+	return bits.Len(uint(i+1))%2 == 1
+}
@@ -0,0 +1,101 @@
+package piority
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// checkMinMax checks every node against its descendants by the level
+func checkMinMax(t *testing.T, h *MinMaxHeap) {
+	for i := 1; i < len(h.vals); i++ {
+		for a := (i - 1) / 2; ; a = (a - 1) / 2 { // all ancestors
+			if isMinLevel(a) && h.less(h.vals[i], h.vals[a]) {
+				t.Fatalf("node %d %v is less than its min ancestor %d %v", i, h.vals[i], a, h.vals[a])
+			}
+			if !isMinLevel(a) && h.less(h.vals[a], h.vals[i]) {
+				t.Fatalf("node %d %v is greater than its max ancestor %d %v", i, h.vals[i], a, h.vals[a])
+			}
+			if a == 0 {
+				break
+			}
+		}
+	}
+}
+
+func TestMinMaxHeap(t *testing.T) {
+	h := NewMinMaxHeap(intLess)
+	sorted := make([]int, 0) // the same elements in ascending order
+	for i := 0; i < 5000; i++ {
+		switch rand.Intn(4) {
+		case 0, 1:
+			v := rand.Intn(1000)
+			h.Push(v)
+			j := sort.SearchInts(sorted, v)
+			sorted = append(sorted, 0)
+			copy(sorted[j+1:], sorted[j:])
+			sorted[j] = v
+		case 2:
+			if len(sorted) == 0 {
+				if h.PopMin() != nil || h.PeekMin() != nil {
+					t.Fatalf("expected nil from empty heap")
+				}
+				continue
+			}
+			if got := h.PeekMin(); got != sorted[0] {
+				t.Fatalf("peek min expected %d, got %v", sorted[0], got)
+			}
+			if got := h.PopMin(); got != sorted[0] {
+				t.Fatalf("pop min expected %d, got %v", sorted[0], got)
+			}
+			sorted = sorted[1:]
+		default:
+			if len(sorted) == 0 {
+				if h.PopMax() != nil || h.PeekMax() != nil {
+					t.Fatalf("expected nil from empty heap")
+				}
+				continue
+			}
+			max := sorted[len(sorted)-1]
+			if got := h.PeekMax(); got != max {
+				t.Fatalf("peek max expected %d, got %v", max, got)
+			}
+			if got := h.PopMax(); got != max {
+				t.Fatalf("pop max expected %d, got %v", max, got)
+			}
+			sorted = sorted[:len(sorted)-1]
+		}
+		if i%100 == 0 {
+			checkMinMax(t, &h)
+		}
+	}
+	if h.Size() != len(sorted) {
+		t.Fatalf("expected size %d, got %d", len(sorted), h.Size())
+	}
+}
+
+func TestNewMinMaxHeapFrom(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 7, 100, 1000} {
+		vals := randomInts(n, 100)
+		ivals := make([]interface{}, n)
+		for i, v := range vals {
+			ivals[i] = v
+		}
+		h := NewMinMaxHeapFrom(ivals, intLess)
+		checkMinMax(t, &h)
+		sort.Ints(vals)
+		for len(vals) > 0 { // pop from both ends in turns
+			if got := h.PopMin(); got != vals[0] {
+				t.Fatalf("n %d pop min expected %d, got %v", n, vals[0], got)
+			}
+			vals = vals[1:]
+			if len(vals) == 0 {
+				break
+			}
+			if got := h.PopMax(); got != vals[len(vals)-1] {
+				t.Fatalf("n %d pop max expected %d, got %v", n, vals[len(vals)-1], got)
+			}
+			vals = vals[:len(vals)-1]
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package piority
+
+// owner identifies the heap of a node, for the heaps whose Merge moves nodes without visiting them.
+// Merge points the owner of other to the one of h, and gives other a new one, so a node finds its
+// heap by following the parents, with path halving, amortized nearly o(1).
+type owner struct {
+	parent *owner
+}
+
+func (o *owner) find() *owner {
+	for o.parent != nil {
+		if o.parent.parent != nil {
+			o.parent = o.parent.parent
+		}
+		o = o.parent
+	}
+	return o
+}
@@ -0,0 +1,177 @@
+package piority
+
+var _ AddressableQueue = (*PairingHeap)(nil)
+
+// PairingHeap is a multiway tree with heap order. Push, Merge and DecreaseKey link trees in o(1),
+// Pop links the children of root in pairs from left to right, then from right to left,
+// amortized o(logn). Handles are the nodes.
+type PairingHeap struct {
+	root  *pairingNode
+	size  int
+	less  Less
+	owner *owner // of the nodes of h
+}
+
+type pairingNode struct {
+	v       interface{}
+	child   *pairingNode // the leftmost child
+	sibling *pairingNode // the next sibling to the right
+	prev    *pairingNode // the previous sibling, or the parent of the leftmost child
+	removed bool
+	owner   *owner
+}
+
+func (x *pairingNode) Value() interface{} {
+	return x.v
+}
+
+// NewPairingHeap returns an empty heap ordered by less, by ItemLess if nil
+func NewPairingHeap(less Less) PairingHeap {
+	if less == nil {
+		less = ItemLess
+	}
+	return PairingHeap{less: less, owner: &owner{}}
+}
+
+func (h *PairingHeap) Size() int {
+	return h.size
+}
+
+func (h *PairingHeap) Empty() bool {
+	return h.size == 0
+}
+
+// Push v to the heap
+func (h *PairingHeap) Push(v interface{}) {
+	h.Insert(v)
+}
+
+// Insert pushes v, and returns its handle, o(1)
+func (h *PairingHeap) Insert(v interface{}) Handle {
+	x := &pairingNode{v: v, owner: h.owner}
+	h.root = h.link(h.root, x)
+	h.size++
+	return x
+}
+
+// Peek returns the least element, nil if empty
+func (h *PairingHeap) Peek() interface{} {
+	if h.root == nil {
+		return nil
+	}
+	return h.root.v
+}
+
+// Pop removes and returns the least element, nil if empty
+func (h *PairingHeap) Pop() interface{} {
+	if h.root == nil {
+		return nil
+	}
+	x := h.root
+	h.root = h.combine(x.child)
+	h.size--
+	x.child, x.removed = nil, true
+	return x.v
+}
+
+// DecreaseKey changes the element of x to v, which must not be greater than the old one, otherwise panic.
+// x is cut from its parent and linked to root, o(1).
+func (h *PairingHeap) DecreaseKey(x Handle, v interface{}) {
+	nd := h.node(x)
+	if h.less(nd.v, v) {
+		panic("PairingHeap.DecreaseKey: new value is greater")
+	}
+	nd.v = v
+	if nd != h.root {
+		h.cut(nd)
+		h.root = h.link(h.root, nd)
+	}
+}
+
+// Remove the element of x, and returns it
+func (h *PairingHeap) Remove(x Handle) interface{} {
+	nd := h.node(x)
+	if nd == h.root {
+		return h.Pop()
+	}
+	h.cut(nd)
+	h.root = h.link(h.root, h.combine(nd.child))
+	h.size--
+	nd.child, nd.removed = nil, true
+	return nd.v
+}
+
+// Merge moves all elements of other into h, other becomes empty. Both must be in the same order.
+func (h *PairingHeap) Merge(other *PairingHeap) {
+	if h == other {
+		return
+	}
+	h.root = h.link(h.root, other.root)
+	h.size += other.size
+	other.root, other.size = nil, 0
+	other.owner.parent, other.owner = h.owner, &owner{} // the moved nodes are of h now
+}
+
+func (h *PairingHeap) node(x Handle) *pairingNode {
+	nd, ok := x.(*pairingNode)
+	if !ok || nd.removed || nd.owner.find() != h.owner {
+		panic("PairingHeap: invalid handle")
+	}
+	return nd
+}
+
+// link makes the greater root of a and b the leftmost child of the other, returns the new root
+func (h *PairingHeap) link(a *pairingNode, b *pairingNode) *pairingNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if h.less(b.v, a.v) {
+		a, b = b, a
+	}
+	b.prev, b.sibling = a, a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	a.child = b
+	return a
+}
+
+// cut x with its subtree from its parent and siblings
+func (h *PairingHeap) cut(x *pairingNode) {
+	if x.prev.child == x { // the leftmost child
+		x.prev.child = x.sibling
+	} else {
+		x.prev.sibling = x.sibling
+	}
+	if x.sibling != nil {
+		x.sibling.prev = x.prev
+	}
+	x.prev, x.sibling = nil, nil
+}
+
+// combine links the sibling list from first in two passes, returns the new root
+func (h *PairingHeap) combine(first *pairingNode) *pairingNode {
+	if first == nil {
+		return nil
+	}
+	pairs := make([]*pairingNode, 0, 8)
+	for x := first; x != nil; { // left to right, link in pairs
+		a, b := x, x.sibling
+		if b == nil {
+			x = nil
+		} else {
+			x = b.sibling
+			b.prev, b.sibling = nil, nil
+		}
+		a.prev, a.sibling = nil, nil
+		pairs = append(pairs, h.link(a, b))
+	}
+	root := pairs[len(pairs)-1]
+	for i := len(pairs) - 2; i >= 0; i-- { // right to left, link to the accumulated one
+		root = h.link(pairs[i], root)
+	}
+	return root
+}
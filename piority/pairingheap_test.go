@@ -0,0 +1,44 @@
+package piority
+
+import (
+	"testing"
+)
+
+// checkPairing checks heap order and prev links, returns the number of nodes
+func checkPairing(t *testing.T, x *pairingNode, less Less) int {
+	n := 0
+	for ; x != nil; x = x.sibling {
+		n += 1 + checkPairing(t, x.child, less)
+		for c, prev := x.child, x; c != nil; c, prev = c.sibling, c {
+			if c.prev != prev {
+				t.Fatalf("node %v has broken prev link", c.v)
+			}
+			if less(c.v, x.v) {
+				t.Fatalf("child %v is less than %v", c.v, x.v)
+			}
+		}
+	}
+	return n
+}
+
+func TestPairingHeap_Merge(t *testing.T) {
+	a, b := NewPairingHeap(intLess), NewPairingHeap(intLess)
+	va, vb := randomInts(500, 1000), randomInts(700, 1000)
+	for _, v := range va {
+		a.Push(v)
+	}
+	for _, v := range vb {
+		b.Push(v)
+	}
+	a.Pop()
+	b.Pop() // let them have some structure
+	va, vb = sortedTail(va), sortedTail(vb)
+	a.Merge(&b)
+	if !b.Empty() {
+		t.Fatalf("expected other empty after merge")
+	}
+	if n := checkPairing(t, a.root, intLess); n != a.Size() {
+		t.Fatalf("expected %d nodes, got %d", a.Size(), n)
+	}
+	popAll(t, "PairingHeap", a.Pop, append(va, vb...))
+}
@@ -0,0 +1,154 @@
+package piority
+
+import (
+	"github.com/joexzh/dsa/list"
+	"math/bits"
+	"time"
+)
+
+const (
+	wheelBits   = 6
+	wheelSlots  = 1 << wheelBits
+	wheelMask   = wheelSlots - 1
+	wheelLevels = (64 + wheelBits - 1) / wheelBits // covers all uint64 ticks
+)
+
+// timingWheel is a hierarchical timing wheel, a delayStore of o(1) add and remove.
+//
+// Time is counted in ticks since start, a tick number is split into groups of wheelBits bits, and
+// level k has a slot for each value of group k. An item is in level k, if the highest group its tick
+// differs from the current tick is k, and in the slot of its group k. When the current tick reaches
+// a slot of level k, items in it are cascaded down to lower levels, or released at level 0.
+// Empty slots are skipped by the bitmaps of occupied slots.
+type timingWheel struct {
+	start    time.Time
+	tick     time.Duration
+	cur      uint64 // the current tick
+	slots    [wheelLevels][wheelSlots]list.LinkedList
+	occupied [wheelLevels]uint64
+	ready    list.LinkedList // released items, Data is *Delayed
+	n        int
+}
+
+func newTimingWheel(start time.Time, tick time.Duration) *timingWheel {
+	w := &timingWheel{start: start, tick: tick, ready: list.NewLinkedList()}
+	for k := range w.slots {
+		for s := range w.slots[k] {
+			w.slots[k][s] = list.NewLinkedList()
+		}
+	}
+	return w
+}
+
+func (w *timingWheel) add(d *Delayed) {
+	d.tick = 0
+	if dur := d.deadline.Sub(w.start); dur > 0 { // rounded up, so never released early
+		d.tick = uint64((dur + w.tick - 1) / w.tick)
+	}
+	w.place(d)
+	w.n++
+}
+
+func (w *timingWheel) remove(d *Delayed) {
+	d.slot.Remove(d.node)
+	if d.slot != &w.ready && d.slot.Size() == 0 {
+		k, s := w.slotOf(d.tick)
+		w.occupied[k] &^= 1 << s
+	}
+	d.slot, d.node = nil, nil
+	w.n--
+}
+
+func (w *timingWheel) poll(now time.Time) *Delayed {
+	if dur := now.Sub(w.start); dur > 0 {
+		w.advance(uint64(dur / w.tick))
+	}
+	nd := w.ready.First()
+	if !nd.Valid() {
+		return nil
+	}
+	d := nd.Data.(*Delayed)
+	w.remove(d)
+	return d
+}
+
+func (w *timingWheel) next(now time.Time) (time.Time, bool) {
+	if w.ready.Size() > 0 {
+		return now, true
+	}
+	t, ok := w.nextEvent()
+	if !ok {
+		return time.Time{}, false
+	}
+	return w.start.Add(time.Duration(t) * w.tick), true
+}
+
+func (w *timingWheel) size() int {
+	return w.n
+}
+
+// place d to the ready list if it's expired, or to its slot
+func (w *timingWheel) place(d *Delayed) {
+	if d.tick <= w.cur {
+		d.slot = &w.ready
+	} else {
+		k, s := w.slotOf(d.tick)
+		d.slot = &w.slots[k][s]
+		w.occupied[k] |= 1 << s
+	}
+	d.slot.InsertEnd(d)
+	d.node = d.slot.Last()
+}
+
+// slotOf returns the level and slot of a future tick
+func (w *timingWheel) slotOf(tick uint64) (int, uint) {
+	k := (bits.Len64(tick^w.cur) - 1) / wheelBits
+	return k, uint(tick>>(k*wheelBits)) & wheelMask
+}
+
+// advance the current tick to target, cascading or releasing items of the slots passed
+func (w *timingWheel) advance(target uint64) {
+	for w.cur < target {
+		t, ok := w.nextEvent()
+		if !ok || t > target {
+			w.cur = target
+			return
+		}
+		w.cur = t
+		for k := wheelLevels - 1; k >= 0; k-- { // higher levels first, they may cascade to lower ones
+			if k > 0 && w.cur&(1<<(k*wheelBits)-1) != 0 {
+				continue
+			}
+			s := uint(w.cur>>(k*wheelBits)) & wheelMask
+			if w.occupied[k]&(1<<s) == 0 {
+				continue
+			}
+			w.occupied[k] &^= 1 << s
+			slot := &w.slots[k][s]
+			for nd := slot.First(); nd.Valid(); nd = slot.First() {
+				d := nd.Data.(*Delayed)
+				slot.Remove(nd)
+				w.place(d)
+			}
+		}
+	}
+}
+
+// nextEvent returns the nearest tick after the current one, where an occupied slot is reached
+func (w *timingWheel) nextEvent() (uint64, bool) {
+	var next uint64
+	found := false
+	for k := 0; k < wheelLevels; k++ {
+		g := uint(w.cur>>(k*wheelBits)) & wheelMask
+		m := w.occupied[k] &^ (2<<g - 1) // slots after the current one
+		if m == 0 {
+			continue
+		}
+		s := uint64(bits.TrailingZeros64(m))
+		base := w.cur &^ (1<<((k+1)*wheelBits) - 1)
+		if t := base | s<<(k*wheelBits); !found || t < next {
+			next, found = t, true
+		}
+	}
+	return next, found
+}
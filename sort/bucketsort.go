@@ -0,0 +1,94 @@
+package sort
+
+import "math"
+
+// BucketSort sorts a in ascending order by distributing the values into len(a) buckets evenly over
+// [min, max] of a, then sorting the buckets by insertion sort.
+// o(n) expected for uniformly distributed values, o(n^2) if most fall into a few buckets, o(n) extra
+// space. NaNs go first, then the infinities go to both ends, like the standard library.
+func BucketSort(a []float64) {
+	lo, hi := 0, len(a)
+	for i, v := range a {
+		if math.IsNaN(v) {
+			a[lo], a[i] = a[i], a[lo]
+			lo++
+		}
+	}
+	for i := lo; i < hi; i++ {
+		if math.IsInf(a[i], -1) {
+			a[lo], a[i] = a[i], a[lo]
+			lo++
+		}
+	}
+	for i := hi - 1; i >= lo; i-- {
+		if math.IsInf(a[i], 1) {
+			hi--
+			a[hi], a[i] = a[i], a[hi]
+		}
+	}
+	bucketSort(a[lo:hi])
+}
+
+// bucketSort sorts the finite values of a
+func bucketSort(a []float64) {
+	n := len(a)
+	if n < 2 {
+		return
+	}
+	min, max := a[0], a[0]
+	for _, v := range a {
+		min, max = math.Min(min, v), math.Max(max, v)
+	}
+	if min == max {
+		return
+	}
+	width, halve := max-min, false
+	if math.IsInf(width, 1) { // halved, so max-min doesn't overflow
+		width, halve = max/2-min/2, true
+	}
+	if !(width > 0) || math.IsInf(width, 1) { // never for finite values, but no bucket is valid then
+		insertionSortFloat64(a)
+		return
+	}
+	bucket := func(v float64) int {
+		d := v - min
+		if halve {
+			d = v/2 - min/2
+		}
+		// d/width is in [0, 1], so the subnormal width doesn't overflow a scale of n/width
+		b := int(d / width * float64(n))
+		if b < 0 {
+			return 0
+		}
+		return minInt(b, n-1)
+	}
+	count := make([]int, n+1) // shifted by one to count, then the start of each bucket
+	for _, v := range a {
+		count[bucket(v)+1]++
+	}
+	for b := 1; b <= n; b++ {
+		count[b] += count[b-1]
+	}
+	aux := make([]float64, n)
+	start := append([]int(nil), count...)
+	for _, v := range a {
+		b := bucket(v)
+		aux[count[b]] = v
+		count[b]++
+	}
+	for b := 0; b < n; b++ {
+		insertionSortFloat64(aux[start[b]:start[b+1]])
+	}
+	copy(a, aux)
+}
+
+func insertionSortFloat64(a []float64) {
+	for i := 1; i < len(a); i++ {
+		v := a[i]
+		j := i
+		for ; j > 0 && v < a[j-1]; j-- {
+			a[j] = a[j-1]
+		}
+		a[j] = v
+	}
+}
@@ -0,0 +1,71 @@
+package sort
+
+import (
+	"math"
+	"math/rand"
+	stdsort "sort"
+	"testing"
+)
+
+func TestBucketSort(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	gens := map[string]func() float64{
+		"Uniform":     r.Float64,
+		"Normal":      r.NormFloat64,
+		"Exponential": r.ExpFloat64,
+		"Huge": func() float64 {
+			return (r.Float64() - 0.5) * math.MaxFloat64 * 1.9
+		},
+		"Subnormal": func() float64 {
+			return math.SmallestNonzeroFloat64 * float64(r.Intn(5))
+		},
+		"NearlyEqual": func() float64 {
+			return 1 + float64(r.Intn(4))*2.220446049250313e-16 // a few ulps apart
+		},
+		"Special": func() float64 {
+			return []float64{math.NaN(), math.Inf(1), math.Inf(-1), 0, math.Copysign(0, -1), 1, -1}[r.Intn(7)]
+		},
+	}
+	for name, gen := range gens {
+		for _, n := range []int{0, 1, 2, 100, 10000} {
+			a := make([]float64, n)
+			for i := range a {
+				a[i] = gen()
+			}
+			expected := append([]float64(nil), a...)
+			stdsort.Float64s(expected)
+			BucketSort(a)
+			for i := range a {
+				if a[i] != expected[i] && !(math.IsNaN(a[i]) && math.IsNaN(expected[i])) {
+					t.Fatalf("%s of %d: index %d expected %v, got %v", name, n, i, expected[i], a[i])
+				}
+			}
+		}
+	}
+	a := []float64{5e-324, 0, 1e-323, 5e-324} // the width of the buckets is subnormal
+	BucketSort(a)
+	if a[0] != 0 || a[1] != 5e-324 || a[2] != 5e-324 || a[3] != 1e-323 {
+		t.Fatalf("expected sorted, got %v", a)
+	}
+}
+
+func BenchmarkBucketSort(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	src := make([]float64, benchSize)
+	for i := range src {
+		src[i] = r.Float64()
+	}
+	a := make([]float64, benchSize)
+	b.Run("BucketSort", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			copy(a, src)
+			BucketSort(a)
+		}
+	})
+	b.Run("Float64s", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			copy(a, src)
+			stdsort.Float64s(a)
+		}
+	})
+}
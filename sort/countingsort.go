@@ -0,0 +1,64 @@
+package sort
+
+import "github.com/joexzh/dsa"
+
+// ranges of CountingSortInt64 up to countingRange are counted even if wider than the length
+const countingRange = 1 << 16
+
+// CountingSortInt64 sorts a in ascending order by counting each key in [min, max] of a.
+// o(n+k) time and o(k) extra space for the range k = max-min+1, only for small ranges. If k is wider
+// than both the length and countingRange, it sorts by RadixSortInt64 instead, so k doesn't exhaust
+// memory.
+func CountingSortInt64(a []dsa.Int64) {
+	if len(a) < 2 {
+		return
+	}
+	min, max := a[0], a[0]
+	for _, v := range a {
+		if v < min {
+			min = v
+		} else if v > max {
+			max = v
+		}
+	}
+	k := uint64(max) - uint64(min) // +1 overflows for the full range
+	if k >= countingRange && k >= uint64(len(a)) {
+		RadixSortInt64(a)
+		return
+	}
+	count := make([]int, k+1)
+	for _, v := range a {
+		count[uint64(v)-uint64(min)]++
+	}
+	i := 0
+	for k, c := range count {
+		for ; c > 0; c-- {
+			a[i] = min + dsa.Int64(k)
+			i++
+		}
+	}
+}
+
+// CountingSortFunc sorts a stably by the int keys of its values, which must be in [0, k), otherwise
+// panic. o(n+k) time, o(n+k) extra space.
+func CountingSortFunc(a []interface{}, key func(v interface{}) int, k int) {
+	keys := make([]int, len(a))
+	count := make([]int, k+1) // shifted by one to count, then the start of each key
+	for i, v := range a {
+		c := key(v)
+		if c < 0 || c >= k {
+			panic("CountingSortFunc: key out of range")
+		}
+		keys[i] = c
+		count[c+1]++
+	}
+	for c := 1; c <= k; c++ {
+		count[c] += count[c-1]
+	}
+	aux := make([]interface{}, len(a))
+	for i, v := range a {
+		aux[count[keys[i]]] = v
+		count[keys[i]]++
+	}
+	copy(a, aux)
+}
@@ -0,0 +1,80 @@
+package sort
+
+import (
+	"github.com/joexzh/dsa"
+	"math"
+	"math/rand"
+	stdsort "sort"
+	"testing"
+)
+
+func TestCountingSortInt64(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 100, 10000} {
+		a := make([]dsa.Int64, n)
+		count := make(map[dsa.Int64]int)
+		for i := range a {
+			a[i] = dsa.Int64(rand.Intn(200) - 100)
+			count[a[i]]++
+		}
+		CountingSortInt64(a)
+		for i, v := range a {
+			if i > 0 && v < a[i-1] {
+				t.Fatalf("n %d: index %d %d is less than %d", n, i, v, a[i-1])
+			}
+			count[v]--
+		}
+		for v, c := range count {
+			if c != 0 {
+				t.Fatalf("n %d: %d count differs by %d", n, v, c)
+			}
+		}
+	}
+	a := []dsa.Int64{math.MaxInt64, math.MaxInt64 - 1, math.MaxInt64 - 2} // the range at the edge
+	CountingSortInt64(a)
+	if a[0] != math.MaxInt64-2 || a[2] != math.MaxInt64 {
+		t.Fatalf("expected sorted, got %v", a)
+	}
+	for _, a := range [][]dsa.Int64{ // the ranges too wide to count
+		{math.MaxInt64, math.MinInt64},
+		{1 << 40, 0, 1 << 40, -1},
+		{math.MaxInt64, 0, math.MinInt64, 7, math.MinInt64},
+	} {
+		expected := append([]dsa.Int64(nil), a...)
+		stdsort.Slice(expected, func(i, j int) bool { return expected[i] < expected[j] })
+		CountingSortInt64(a)
+		for i := range a {
+			if a[i] != expected[i] {
+				t.Fatalf("expected %v, got %v", expected, a)
+			}
+		}
+	}
+}
+
+func TestCountingSortFunc(t *testing.T) {
+	for _, p := range patterns {
+		keys := p.gen(rand.New(rand.NewSource(1)), 10000)
+		recs := make([]interface{}, len(keys))
+		expected := make([]record, len(keys))
+		for i, k := range keys {
+			recs[i] = record{k, i}
+			expected[i] = record{k, i}
+		}
+		stdsort.SliceStable(expected, func(i, j int) bool { return expected[i].k < expected[j].k })
+
+		CountingSortFunc(recs, func(v interface{}) int { return v.(record).k }, 10001)
+		for i, v := range recs {
+			if v != expected[i] {
+				t.Fatalf("%s: index %d expected %v, got %v", p.name, i, expected[i], v)
+			}
+		}
+	}
+}
+
+func TestCountingSortFunc_OutOfRange(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic of key out of range")
+		}
+	}()
+	CountingSortFunc([]interface{}{1, 5}, func(v interface{}) int { return v.(int) }, 5)
+}
@@ -0,0 +1,257 @@
+package sort
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"github.com/joexzh/dsa"
+	"github.com/joexzh/dsa/piority"
+	"io"
+	"os"
+	"strings"
+)
+
+// Codec reads and writes the records of ExternalSort
+type Codec interface {
+	// Read the next record, io.EOF if no more
+	Read(r *bufio.Reader) (interface{}, error)
+	Write(w *bufio.Writer, v interface{}) error
+	// Size estimates the memory held by the record in bytes, to keep a run in the budget
+	Size(v interface{}) int
+}
+
+// LineCodec reads and writes dsa.String records of lines, without the trailing '\n'
+func LineCodec() Codec {
+	return lineCodec{}
+}
+
+type lineCodec struct{}
+
+func (lineCodec) Read(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err == io.EOF && line != "" { // the last line without '\n'
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dsa.String(strings.TrimSuffix(line, "\n")), nil
+}
+
+func (lineCodec) Write(w *bufio.Writer, v interface{}) error {
+	if _, err := w.WriteString(string(v.(dsa.String))); err != nil {
+		return err
+	}
+	return w.WriteByte('\n')
+}
+
+func (lineCodec) Size(v interface{}) int {
+	return len(v.(dsa.String)) + 32 // the string and interface headers
+}
+
+// Int64Codec reads and writes dsa.Int64 records of 8 bytes, big endian
+func Int64Codec() Codec {
+	return int64Codec{}
+}
+
+type int64Codec struct{}
+
+func (int64Codec) Read(r *bufio.Reader) (interface{}, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return nil, err // io.ErrUnexpectedEOF if a record is cut
+	}
+	return dsa.Int64(binary.BigEndian.Uint64(b[:])), nil
+}
+
+func (int64Codec) Write(w *bufio.Writer, v interface{}) error {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v.(dsa.Int64)))
+	_, err := w.Write(b[:])
+	return err
+}
+
+func (int64Codec) Size(v interface{}) int {
+	return 16
+}
+
+// ExternalOptions tunes ExternalSort, the zero values are the defaults
+type ExternalOptions struct {
+	Memory  int    // budget of the records of a run in bytes, by Codec.Size, default 64MiB
+	TempDir string // directory of the run files, default os.TempDir()
+	FanIn   int    // max runs merged at once, more runs are merged in passes, default 64
+}
+
+func (o *ExternalOptions) withDefaults() ExternalOptions {
+	opts := ExternalOptions{Memory: 64 << 20, FanIn: 64}
+	if o != nil {
+		if o.Memory > 0 {
+			opts.Memory = o.Memory
+		}
+		opts.TempDir = o.TempDir
+		if o.FanIn > 1 {
+			opts.FanIn = o.FanIn
+		}
+	}
+	return opts
+}
+
+// ExternalSort sorts the records of r stably in the order of less, by ItemLess if nil, and writes them
+// to w, for the data larger than memory.
+// It reads records into memory until the budget is full, sorts them by TimSort and spills the run to
+// a temp file, then merges the runs by a heap of their heads, FanIn runs a pass, so the open files are
+// bounded. If all records fit in one run, they go to w directly without temp files.
+// The temp files are removed before it returns, even on errors.
+func ExternalSort(r io.Reader, w io.Writer, codec Codec, less Less, opts *ExternalOptions) error {
+	o := opts.withDefaults()
+	if less == nil {
+		less = ItemLess
+	}
+	var temps []string // all temp files created
+	defer func() {
+		for _, name := range temps {
+			os.Remove(name)
+		}
+	}()
+	newTemp := func() (*os.File, error) {
+		f, err := os.CreateTemp(o.TempDir, "extsort-*")
+		if err != nil {
+			return nil, fmt.Errorf("sort: create run: %w", err)
+		}
+		temps = append(temps, f.Name())
+		return f, nil
+	}
+
+	br := bufio.NewReader(r)
+	var runs []string
+	var recs []interface{}
+	for eof := false; !eof; {
+		var err error
+		recs, eof, err = readRun(br, codec, o.Memory, recs[:0])
+		if err != nil {
+			return fmt.Errorf("sort: read records: %w", err)
+		}
+		if len(recs) == 0 && len(runs) > 0 { // the input ended at the budget of the last run
+			break
+		}
+		TimSortFunc(recs, less)
+		if eof && len(runs) == 0 { // all in memory
+			if err := writeRun(w, codec, recs); err != nil {
+				return fmt.Errorf("sort: write records: %w", err)
+			}
+			return nil
+		}
+		f, err := newTemp()
+		if err != nil {
+			return err
+		}
+		err = writeRun(f, codec, recs)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("sort: spill run: %w", err)
+		}
+		runs = append(runs, f.Name())
+	}
+	recs = nil // release the last run before merging
+
+	for len(runs) > o.FanIn { // merge passes, the order of runs is kept for stability
+		next := make([]string, 0, (len(runs)+o.FanIn-1)/o.FanIn)
+		for i := 0; i < len(runs); i += o.FanIn {
+			f, err := newTemp()
+			if err != nil {
+				return err
+			}
+			err = mergeRuns(f, codec, less, runs[i:minInt(i+o.FanIn, len(runs))])
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+			next = append(next, f.Name())
+		}
+		for _, name := range runs {
+			os.Remove(name)
+		}
+		runs = next
+	}
+	return mergeRuns(w, codec, less, runs)
+}
+
+// readRun appends records to recs until their size reaches memory, returns true if r is done
+func readRun(r *bufio.Reader, codec Codec, memory int, recs []interface{}) ([]interface{}, bool, error) {
+	size := 0
+	for size < memory {
+		v, err := codec.Read(r)
+		if err == io.EOF {
+			return recs, true, nil
+		}
+		if err != nil {
+			return recs, false, err
+		}
+		recs = append(recs, v)
+		size += codec.Size(v)
+	}
+	return recs, false, nil
+}
+
+func writeRun(w io.Writer, codec Codec, recs []interface{}) error {
+	bw := bufio.NewWriter(w)
+	for _, v := range recs {
+		if err := codec.Write(bw, v); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// runReader is the head record of a run file in the merge
+type runReader struct {
+	r *bufio.Reader
+	v interface{}
+	i int // index of the run, the earlier run goes first of equal records
+}
+
+// mergeRuns merges the sorted run files to w by a heap of their heads
+func mergeRuns(w io.Writer, codec Codec, less Less, runs []string) error {
+	h := piority.NewBinaryHeap(func(a interface{}, b interface{}) bool {
+		x, y := a.(*runReader), b.(*runReader)
+		if less(x.v, y.v) {
+			return true
+		}
+		return !less(y.v, x.v) && x.i < y.i
+	})
+	for i, name := range runs {
+		f, err := os.Open(name)
+		if err != nil {
+			return fmt.Errorf("sort: open run: %w", err)
+		}
+		defer f.Close()
+		rr := &runReader{r: bufio.NewReader(f), i: i}
+		if rr.v, err = codec.Read(rr.r); err == nil {
+			h.Push(rr)
+		} else if err != io.EOF {
+			return fmt.Errorf("sort: read run: %w", err)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for !h.Empty() {
+		rr := h.Pop().(*runReader)
+		if err := codec.Write(bw, rr.v); err != nil {
+			return fmt.Errorf("sort: write records: %w", err)
+		}
+		var err error
+		if rr.v, err = codec.Read(rr.r); err == nil {
+			h.Push(rr)
+		} else if err != io.EOF {
+			return fmt.Errorf("sort: read run: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("sort: write records: %w", err)
+	}
+	return nil
+}
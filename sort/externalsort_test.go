@@ -0,0 +1,163 @@
+package sort
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/joexzh/dsa"
+	"io"
+	"math/rand"
+	"os"
+	stdsort "sort"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func encodeAll(t *testing.T, codec Codec, recs []interface{}) []byte {
+	var buf bytes.Buffer
+	if err := writeRun(&buf, codec, recs); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decodeAll(t *testing.T, codec Codec, data []byte) []interface{} {
+	recs, eof, err := readRun(bufio.NewReader(bytes.NewReader(data)), codec, int(^uint(0)>>1), nil)
+	if err != nil || !eof {
+		t.Fatalf("decode expected eof, got %v %v", eof, err)
+	}
+	return recs
+}
+
+func tempFiles(t *testing.T, dir string) int {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(files)
+}
+
+func TestExternalSort_Int64(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, c := range []struct {
+		n     int
+		opts  ExternalOptions
+		spill bool
+	}{
+		{0, ExternalOptions{}, false},
+		{1000, ExternalOptions{}, false},          // in memory
+		{1000, ExternalOptions{Memory: 16}, true}, // a run of each record
+		{100000, ExternalOptions{Memory: 16 * 3000}, true},
+		{100000, ExternalOptions{Memory: 16 * 5000}, true},           // the input ends at the budget of a run
+		{100000, ExternalOptions{Memory: 16 * 1000, FanIn: 4}, true}, // merge passes
+	} {
+		recs := make([]interface{}, c.n)
+		expected := make([]int64, c.n)
+		for i := range recs {
+			v := r.Int63() - r.Int63()
+			recs[i], expected[i] = dsa.Int64(v), v
+		}
+		stdsort.Slice(expected, func(i, j int) bool { return expected[i] < expected[j] })
+
+		dir := t.TempDir()
+		c.opts.TempDir = dir
+		var out bytes.Buffer
+		if err := ExternalSort(bytes.NewReader(encodeAll(t, Int64Codec(), recs)), &out, Int64Codec(), nil, &c.opts); err != nil {
+			t.Fatal(err)
+		}
+		got := decodeAll(t, Int64Codec(), out.Bytes())
+		if len(got) != c.n {
+			t.Fatalf("n %d: expected %d records, got %d", c.n, c.n, len(got))
+		}
+		for i, v := range got {
+			if v != dsa.Int64(expected[i]) {
+				t.Fatalf("n %d: index %d expected %d, got %v", c.n, i, expected[i], v)
+			}
+		}
+		if n := tempFiles(t, dir); n != 0 {
+			t.Fatalf("n %d: expected temp files removed, %d left", c.n, n)
+		}
+	}
+}
+
+func TestExternalSort_Stable(t *testing.T) {
+	var in strings.Builder
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&in, "%c %d\n", 'a'+rand.Intn(26), i)
+	}
+	byFirst := func(a interface{}, b interface{}) bool {
+		return a.(dsa.String)[0] < b.(dsa.String)[0]
+	}
+	var out bytes.Buffer
+	opts := &ExternalOptions{Memory: 1 << 12, FanIn: 3, TempDir: t.TempDir()}
+	if err := ExternalSort(strings.NewReader(in.String()), &out, LineCodec(), byFirst, opts); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 20000 {
+		t.Fatalf("expected %d lines, got %d", 20000, len(lines))
+	}
+	for i := 1; i < len(lines); i++ {
+		var c0, c1 byte
+		var i0, i1 int
+		fmt.Sscanf(lines[i-1], "%c %d", &c0, &i0)
+		fmt.Sscanf(lines[i], "%c %d", &c1, &i1)
+		if c1 < c0 || c1 == c0 && i1 < i0 {
+			t.Fatalf("line %d %q is out of order after %q", i, lines[i], lines[i-1])
+		}
+	}
+}
+
+func TestLineCodec_LastLine(t *testing.T) {
+	got := decodeAll(t, LineCodec(), []byte("b\n\na"))
+	if len(got) != 3 || got[0] != dsa.String("b") || got[1] != dsa.String("") || got[2] != dsa.String("a") {
+		t.Fatalf("expected b, empty and a, got %v", got)
+	}
+}
+
+func TestExternalSort_Errors(t *testing.T) {
+	dir := t.TempDir()
+	opts := &ExternalOptions{Memory: 16 * 10, TempDir: dir}
+	data := encodeAll(t, Int64Codec(), []interface{}{dsa.Int64(3), dsa.Int64(1), dsa.Int64(2)})
+	var out bytes.Buffer
+
+	failing := errors.New("failing reader")
+	r := iotest.TimeoutReader(bytes.NewReader(append(data, data...))) // fails at the second read
+	if err := ExternalSort(r, &out, Int64Codec(), nil, opts); err == nil {
+		t.Fatal("expected error of the reader")
+	}
+	if err := ExternalSort(iotest.ErrReader(failing), &out, Int64Codec(), nil, opts); !errors.Is(err, failing) {
+		t.Fatalf("expected %v, got %v", failing, err)
+	}
+	cut := bytes.Repeat(data, 10)
+	if err := ExternalSort(bytes.NewReader(cut[:len(cut)-3]), &out, Int64Codec(), nil, opts); err == nil {
+		t.Fatal("expected error of the cut record")
+	}
+	if n := tempFiles(t, dir); n != 0 {
+		t.Fatalf("expected temp files removed, %d left", n)
+	}
+}
+
+func BenchmarkExternalSort(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	recs := make([]interface{}, 1<<20)
+	for i := range recs {
+		recs[i] = dsa.Int64(r.Int63())
+	}
+	var buf bytes.Buffer
+	writeRun(&buf, Int64Codec(), recs)
+	data := buf.Bytes()
+	for _, memory := range []int{1 << 20, 1 << 22, 1 << 30} {
+		b.Run(fmt.Sprintf("Memory%dK", memory>>10), func(b *testing.B) {
+			opts := &ExternalOptions{Memory: memory, TempDir: b.TempDir()}
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if err := ExternalSort(bytes.NewReader(data), io.Discard, Int64Codec(), nil, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
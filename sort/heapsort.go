@@ -1 +1,46 @@
 package sort
+
+import "github.com/joexzh/dsa"
+
+// HeapSort sorts a in ascending order in place, not stable.
+// It heapifies a into a max heap bottom-up, then moves the max to the end one by one.
+// o(nlogn) in the worst case, o(1) extra space.
+func HeapSort(a []dsa.Item) {
+	heapSort(items(a), 0, len(a))
+}
+
+// HeapSortFunc sorts a in the order of less in place, by ItemLess if nil, see HeapSort
+func HeapSortFunc(a []interface{}, less Less) {
+	s := newValues(a, less)
+	heapSort(s, 0, s.Len())
+}
+
+// heapSort sorts s[lo, hi), the heap is rooted at lo
+func heapSort(s sequence, lo int, hi int) {
+	n := hi - lo
+	for i := n/2 - 1; i >= 0; i-- {
+		siftDown(s, lo, i, n)
+	}
+	for n--; n > 0; n-- {
+		s.swap(lo, lo+n)
+		siftDown(s, lo, 0, n)
+	}
+}
+
+// siftDown moves node i of the max heap s[lo, lo+n) down until no child is greater than it
+func siftDown(s sequence, lo int, i int, n int) {
+	for {
+		c := 2*i + 1
+		if c >= n {
+			return
+		}
+		if c+1 < n && s.less(lo+c, lo+c+1) {
+			c++
+		}
+		if !s.less(lo+i, lo+c) {
+			return
+		}
+		s.swap(lo+i, lo+c)
+		i = c
+	}
+}
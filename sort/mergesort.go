@@ -1 +1,88 @@
 package sort
+
+import "github.com/joexzh/dsa"
+
+// MergeSort sorts a in ascending order, stable.
+// It splits top-down, sorts short ranges by insertion sort, and skips merging two halves already in
+// order. o(nlogn), o(n) extra space.
+func MergeSort(a []dsa.Item) {
+	s := items(a)
+	mergeSort(s, make([]interface{}, len(a)/2), 0, len(a))
+}
+
+// MergeSortFunc sorts a in the order of less stably, by ItemLess if nil, see MergeSort
+func MergeSortFunc(a []interface{}, less Less) {
+	s := newValues(a, less)
+	mergeSort(s, make([]interface{}, len(a)/2), 0, len(a))
+}
+
+// MergeSortBottomUp sorts a in ascending order, stable.
+// It sorts blocks by insertion sort, then merges runs of doubled width each pass, without recursion.
+// o(nlogn), o(n) extra space.
+func MergeSortBottomUp(a []dsa.Item) {
+	mergeSortBottomUp(items(a))
+}
+
+// MergeSortBottomUpFunc sorts a in the order of less stably, by ItemLess if nil, see MergeSortBottomUp
+func MergeSortBottomUpFunc(a []interface{}, less Less) {
+	mergeSortBottomUp(newValues(a, less))
+}
+
+// mergeSort sorts s[lo, hi), buf holds at least half of it
+func mergeSort(s sequence, buf []interface{}, lo int, hi int) {
+	if hi-lo < insertionCutoff {
+		insertionSort(s, lo, hi)
+		return
+	}
+	mid := lo + (hi-lo)/2
+	mergeSort(s, buf, lo, mid)
+	mergeSort(s, buf, mid, hi)
+	merge(s, buf, lo, mid, hi)
+}
+
+func mergeSortBottomUp(s sequence) {
+	n := s.Len()
+	for lo := 0; lo < n; lo += insertionCutoff {
+		insertionSort(s, lo, minInt(lo+insertionCutoff, n))
+	}
+	buf := make([]interface{}, n) // the left run may be longer than half at the last pass
+	for width := insertionCutoff; width < n; width *= 2 {
+		for lo := 0; lo < n-width; lo += 2 * width {
+			merge(s, buf, lo, lo+width, minInt(lo+2*width, n))
+		}
+	}
+}
+
+// merge the sorted s[lo, mid) and s[mid, hi), the left one is moved to buf first.
+// Equal ones of the left go first, so it's stable.
+func merge(s sequence, buf []interface{}, lo int, mid int, hi int) {
+	if !s.less(mid, mid-1) { // already in order
+		return
+	}
+	n := mid - lo
+	for i := 0; i < n; i++ {
+		buf[i] = s.at(lo + i)
+	}
+	i, j, k := 0, mid, lo // buf[i], s[j] to s[k]
+	for i < n && j < hi {
+		if s.lessValue(s.at(j), buf[i]) {
+			s.set(k, s.at(j))
+			j++
+		} else {
+			s.set(k, buf[i])
+			i++
+		}
+		k++
+	}
+	for ; i < n; i++ { // the rest of the right one is in place
+		s.set(k, buf[i])
+		k++
+	}
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,189 @@
+package sort
+
+import (
+	"github.com/joexzh/dsa"
+	"runtime"
+)
+
+// ranges shorter than parallelCutoff are sorted sequentially, a goroutine doesn't pay off
+const parallelCutoff = 1 << 12
+
+// ParallelMergeSort sorts a in ascending order stably, the same result as MergeSort.
+// The halves are sorted concurrently, then merged concurrently by splitting the longer run at its
+// middle and the other at the position of that middle by binary search, so the merges don't
+// serialize at the top. At most GOMAXPROCS goroutines run at once, o(n) extra space.
+func ParallelMergeSort(a []dsa.Item) {
+	parallelMergeSort(items(a), make([]interface{}, len(a)), 0, len(a), newWorkers())
+}
+
+// ParallelMergeSortFunc sorts a in the order of less stably, by ItemLess if nil, see
+// ParallelMergeSort. less is called concurrently.
+func ParallelMergeSortFunc(a []interface{}, less Less) {
+	parallelMergeSort(newValues(a, less), make([]interface{}, len(a)), 0, len(a), newWorkers())
+}
+
+// ParallelQuickSort sorts a in ascending order, the same result as QuickSort.
+// It partitions the same way, but sorts the parts concurrently, at most GOMAXPROCS goroutines run
+// at once. The partitions near the top are sequential, which bounds the speedup by about logn.
+func ParallelQuickSort(a []dsa.Item) {
+	parallelQuickSort(items(a), 0, len(a), newWorkers())
+}
+
+// ParallelQuickSortFunc sorts a in the order of less, by ItemLess if nil, see ParallelQuickSort.
+// less is called concurrently.
+func ParallelQuickSortFunc(a []interface{}, less Less) {
+	s := newValues(a, less)
+	parallelQuickSort(s, 0, s.Len(), newWorkers())
+}
+
+// workers holds a token for each goroutine running besides the caller
+type workers chan struct{}
+
+func newWorkers() workers {
+	return make(workers, runtime.GOMAXPROCS(0)-1)
+}
+
+// fork runs f in a new goroutine if a token is free, otherwise runs it at once,
+// returns the function to wait for f
+func (w workers) fork(f func()) (join func()) {
+	select {
+	case w <- struct{}{}:
+		done := make(chan struct{})
+		go func() {
+			f()
+			<-w
+			close(done)
+		}()
+		return func() { <-done }
+	default:
+		f()
+		return func() {}
+	}
+}
+
+// parallelQuickSort is quickSort forking the shorter part if both parts are long
+func parallelQuickSort(s sequence, lo int, hi int, w workers) {
+	var joins []func()
+	for hi-lo >= insertionCutoff {
+		lt, gt := partition3(s, lo, hi, choosePivot(s, lo, hi))
+		if lt-lo < parallelCutoff || hi-gt < parallelCutoff {
+			if lt-lo < hi-gt {
+				quickSort(s, lo, lt)
+				lo = gt
+			} else {
+				quickSort(s, gt, hi)
+				hi = lt
+			}
+			continue
+		}
+		l, r := lo, hi // the loop goes on with the longer part, so the recursion is o(logn) deep
+		if lt-lo < hi-gt {
+			joins = append(joins, w.fork(func() { parallelQuickSort(s, l, lt, w) }))
+			lo = gt
+		} else {
+			joins = append(joins, w.fork(func() { parallelQuickSort(s, gt, r, w) }))
+			hi = lt
+		}
+	}
+	insertionSort(s, lo, hi)
+	for _, join := range joins {
+		join()
+	}
+}
+
+// parallelMergeSort sorts s[lo, hi), aux is as long as s, and only aux[lo, hi) is used
+func parallelMergeSort(s sequence, aux []interface{}, lo int, hi int, w workers) {
+	if hi-lo < parallelCutoff {
+		mergeSort(s, aux[lo:hi], lo, hi)
+		return
+	}
+	mid := lo + (hi-lo)/2
+	join := w.fork(func() { parallelMergeSort(s, aux, lo, mid, w) })
+	parallelMergeSort(s, aux, mid, hi, w)
+	join()
+	if !s.less(mid, mid-1) { // already in order
+		return
+	}
+	parallelCopy(s, aux, lo, hi, w)
+	parallelMerge(s, aux, lo, mid, mid, hi, lo, w)
+}
+
+// parallelCopy copies s[lo, hi) to aux[lo, hi)
+func parallelCopy(s sequence, aux []interface{}, lo int, hi int, w workers) {
+	if hi-lo < parallelCutoff {
+		for i := lo; i < hi; i++ {
+			aux[i] = s.at(i)
+		}
+		return
+	}
+	mid := lo + (hi-lo)/2
+	join := w.fork(func() { parallelCopy(s, aux, lo, mid, w) })
+	parallelCopy(s, aux, mid, hi, w)
+	join()
+}
+
+// parallelMerge merges the sorted aux[a0, a1) and aux[b0, b1) to s from k, the equal ones of the
+// first run go first. The middle of the longer run goes to its final place, and both sides of it
+// are merged concurrently.
+func parallelMerge(s sequence, aux []interface{}, a0 int, a1 int, b0 int, b1 int, k int, w workers) {
+	n1, n2 := a1-a0, b1-b0
+	if n1+n2 < parallelCutoff {
+		mergeFrom(s, aux, a0, a1, b0, b1, k)
+		return
+	}
+	var m, i, j int // aux[m] goes to s[k+(i-a0)+(j-b0)]
+	if n1 >= n2 {
+		m, i = a0+n1/2, a0+n1/2
+		j = b0 + search(n2, func(x int) bool { return !s.lessValue(aux[b0+x], aux[m]) }) // after the less ones
+	} else {
+		m, j = b0+n2/2, b0+n2/2
+		i = a0 + search(n1, func(x int) bool { return s.lessValue(aux[m], aux[a0+x]) }) // after the equal ones
+	}
+	km := k + (i - a0) + (j - b0)
+	s.set(km, aux[m])
+	li, lj := i, j // the left sides end before aux[m]
+	if n1 >= n2 {  // skip aux[m] in its run
+		i++
+	} else {
+		j++
+	}
+	join := w.fork(func() { parallelMerge(s, aux, a0, li, b0, lj, k, w) })
+	parallelMerge(s, aux, i, a1, j, b1, km+1, w)
+	join()
+}
+
+// mergeFrom merges the sorted aux[a0, a1) and aux[b0, b1) to s from k, stable
+func mergeFrom(s sequence, aux []interface{}, a0 int, a1 int, b0 int, b1 int, k int) {
+	for a0 < a1 && b0 < b1 {
+		if s.lessValue(aux[b0], aux[a0]) {
+			s.set(k, aux[b0])
+			b0++
+		} else {
+			s.set(k, aux[a0])
+			a0++
+		}
+		k++
+	}
+	for ; a0 < a1; a0++ {
+		s.set(k, aux[a0])
+		k++
+	}
+	for ; b0 < b1; b0++ {
+		s.set(k, aux[b0])
+		k++
+	}
+}
+
+// search returns the smallest index in [0, n) where f is true, n if none, f must be false then true
+func search(n int, f func(i int) bool) int {
+	l, r := 0, n
+	for l < r {
+		m := int(uint(l+r) >> 1)
+		if f(m) {
+			r = m
+		} else {
+			l = m + 1
+		}
+	}
+	return l
+}
@@ -0,0 +1,83 @@
+package sort
+
+import (
+	"fmt"
+	"github.com/joexzh/dsa"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+// withProcs runs f with GOMAXPROCS of procs, and restores it after
+func withProcs(procs int, f func()) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+	f()
+}
+
+// TestParallel_Sequential checks the parallel sorts put every record at the same index as the
+// sequential ones, on inputs long enough to fork
+func TestParallel_Sequential(t *testing.T) {
+	pairs := []struct {
+		parallel   func(a []interface{}, less Less)
+		sequential func(a []interface{}, less Less)
+		name       string
+	}{
+		{ParallelMergeSortFunc, MergeSortFunc, "MergeSort"},
+		{ParallelQuickSortFunc, QuickSortFunc, "QuickSort"},
+	}
+	r := rand.New(rand.NewSource(1))
+	for _, procs := range []int{1, 2, 4, 7} {
+		for _, p := range patterns {
+			for _, n := range []int{parallelCutoff - 1, parallelCutoff, 3*parallelCutoff + 5, 50000} {
+				keys := p.gen(r, n)
+				for _, pair := range pairs {
+					expected := make([]interface{}, n)
+					for i, k := range keys {
+						expected[i] = record{k, i}
+					}
+					got := append([]interface{}(nil), expected...)
+					pair.sequential(expected, recordLess)
+					withProcs(procs, func() { pair.parallel(got, recordLess) })
+					for i := range got {
+						if got[i] != expected[i] {
+							t.Fatalf("Parallel%s %s of %d, %d procs: index %d expected %v, got %v",
+								pair.name, p.name, n, procs, i, expected[i], got[i])
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkParallel sorts random dsa.Int64 items by GOMAXPROCS, against the sequential sorts
+func BenchmarkParallel(b *testing.B) {
+	const n = 1 << 20
+	r := rand.New(rand.NewSource(1))
+	src := make([]dsa.Item, n)
+	for i := range src {
+		src[i] = dsa.Int64(r.Int63())
+	}
+	a := make([]dsa.Item, n)
+	sorts := []struct {
+		name string
+		sort func(a []dsa.Item)
+	}{
+		{"MergeSort", MergeSort},
+		{"ParallelMergeSort", ParallelMergeSort},
+		{"QuickSort", QuickSort},
+		{"ParallelQuickSort", ParallelQuickSort},
+	}
+	for _, st := range sorts {
+		for _, procs := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("%s/Procs%d", st.name, procs), func(b *testing.B) {
+				withProcs(procs, func() {
+					for i := 0; i < b.N; i++ {
+						copy(a, src)
+						st.sort(a)
+					}
+				})
+			})
+		}
+	}
+}
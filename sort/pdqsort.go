@@ -0,0 +1,241 @@
+package sort
+
+import (
+	"github.com/joexzh/dsa"
+	"math/bits"
+)
+
+// PdqSort sorts a in ascending order, not stable, by pattern-defeating quicksort.
+// It's a quicksort falling back to insertion sort for short ranges and to heap sort when too many
+// bad partitions happened, so it's o(nlogn) in the worst case. Sorted and reversed ranges are found
+// by the pivot samples and finished in o(n), unbalanced partitions are broken by swapping random
+// ones, and a range of all equal to the previous pivot is partitioned off in one pass.
+func PdqSort(a []dsa.Item) {
+	pdqSort(items(a), 0, len(a), bits.Len(uint(len(a))))
+}
+
+// PdqSortFunc sorts a in the order of less, by ItemLess if nil, see PdqSort
+func PdqSortFunc(a []interface{}, less Less) {
+	s := newValues(a, less)
+	pdqSort(s, 0, s.Len(), bits.Len(uint(s.Len())))
+}
+
+type sortedHint int
+
+const (
+	unknownHint sortedHint = iota
+	increasingHint
+	decreasingHint
+)
+
+// pdqSort sorts s[lo, hi), limit is the number of bad partitions allowed before heap sort.
+// s[lo-1], if lo > 0, is a previous pivot not greater than any of s[lo, hi).
+func pdqSort(s sequence, lo int, hi int, limit int) {
+	wasBalanced, wasPartitioned := true, true
+	for {
+		n := hi - lo
+		if n <= insertionCutoff {
+			insertionSort(s, lo, hi)
+			return
+		}
+		if limit == 0 {
+			heapSort(s, lo, hi)
+			return
+		}
+		if !wasBalanced {
+			breakPatterns(s, lo, hi)
+			limit--
+		}
+
+		p, hint := pdqPivot(s, lo, hi)
+		if hint == decreasingHint {
+			reverseRange(s, lo, hi)
+			p = hi - 1 - (p - lo)
+			hint = increasingHint
+		}
+		// the last partition didn't move anything and the samples are in order, likely sorted
+		if wasBalanced && wasPartitioned && hint == increasingHint && partialInsertionSort(s, lo, hi) {
+			return
+		}
+		// the pivot equals the previous one, which is the min, so only the equal ones go left
+		if lo > 0 && !s.less(lo-1, p) {
+			lo = partitionEqual(s, lo, hi, p)
+			continue
+		}
+
+		mid, alreadyPartitioned := pdqPartition(s, lo, hi, p)
+		wasPartitioned = alreadyPartitioned
+		l, r := mid-lo, hi-mid-1
+		if l < r {
+			wasBalanced = l >= n/8
+			pdqSort(s, lo, mid, limit)
+			lo = mid + 1
+		} else {
+			wasBalanced = r >= n/8
+			pdqSort(s, mid+1, hi, limit)
+			hi = mid
+		}
+	}
+}
+
+// pdqPartition moves the pivot at p to mid, the less ones to its left and the others to its right,
+// returns mid, and true if no element was swapped
+func pdqPartition(s sequence, lo int, hi int, p int) (int, bool) {
+	s.swap(lo, p)
+	i, j := lo+1, hi-1 // [i, j] is not partitioned yet
+	for i <= j && s.less(i, lo) {
+		i++
+	}
+	for i <= j && !s.less(j, lo) {
+		j--
+	}
+	if i > j {
+		s.swap(j, lo)
+		return j, true
+	}
+	s.swap(i, j)
+	i++
+	j--
+	for {
+		for i <= j && s.less(i, lo) {
+			i++
+		}
+		for i <= j && !s.less(j, lo) {
+			j--
+		}
+		if i > j {
+			break
+		}
+		s.swap(i, j)
+		i++
+		j--
+	}
+	s.swap(j, lo)
+	return j, false
+}
+
+// partitionEqual moves the ones equal to the pivot at p to the left of the greater ones, assuming
+// none is less than it, returns the index of the first greater one
+func partitionEqual(s sequence, lo int, hi int, p int) int {
+	s.swap(lo, p)
+	i, j := lo+1, hi-1
+	for {
+		for i <= j && !s.less(lo, i) {
+			i++
+		}
+		for i <= j && s.less(lo, j) {
+			j--
+		}
+		if i > j {
+			return i
+		}
+		s.swap(i, j)
+		i++
+		j--
+	}
+}
+
+// partialInsertionSort fixes a few out of order pairs of s[lo, hi) by insertion,
+// returns true if it's sorted then, false if it gives up
+func partialInsertionSort(s sequence, lo int, hi int) bool {
+	const (
+		maxSteps         = 5  // pairs to fix
+		shortestShifting = 50 // shorter ranges are not worth it, the quicksort is fast enough
+	)
+	i := lo + 1
+	for step := 0; step < maxSteps; step++ {
+		for i < hi && !s.less(i, i-1) {
+			i++
+		}
+		if i == hi {
+			return true
+		}
+		if hi-lo < shortestShifting {
+			return false
+		}
+		s.swap(i, i-1)
+		for j := i - 1; j > lo && s.less(j, j-1); j-- { // the smaller one to the left
+			s.swap(j, j-1)
+		}
+		for j := i + 1; j < hi && s.less(j, j-1); j++ { // the greater one to the right
+			s.swap(j, j-1)
+		}
+	}
+	return false
+}
+
+// breakPatterns swaps a few ones around the middle with random ones, so the next pivot samples are
+// unlikely to repeat a bad partition
+func breakPatterns(s sequence, lo int, hi int) {
+	n := hi - lo
+	if n < 8 {
+		return
+	}
+	r := xorshift(n)
+	mask := uint64(1)<<bits.Len(uint(n)) - 1
+	mid := lo + n/4*2 - 1
+	for i := 0; i < 3; i++ {
+		other := int(r.next() & mask)
+		if other >= n {
+			other -= n
+		}
+		s.swap(mid-1+i, lo+other)
+	}
+}
+
+// xorshift is a cheap pseudo random generator seeded by the length, so the sort is deterministic
+type xorshift uint64
+
+func (r *xorshift) next() uint64 {
+	*r ^= *r << 13
+	*r ^= *r >> 7
+	*r ^= *r << 17
+	return uint64(*r)
+}
+
+// pdqPivot returns the index of the pivot of s[lo, hi) like choosePivot, but counts the swaps to
+// order the samples, no swap hints an increasing range and all swapped hints a decreasing one
+func pdqPivot(s sequence, lo int, hi int) (int, sortedHint) {
+	const maxSwaps = 4 * 3
+	n := hi - lo
+	swaps := 0
+	a, b, c := lo+n/4, lo+n/4*2, lo+n/4*3
+	if n >= nintherCutoff {
+		a = medianCount(s, a-1, a, a+1, &swaps)
+		b = medianCount(s, b-1, b, b+1, &swaps)
+		c = medianCount(s, c-1, c, c+1, &swaps)
+	}
+	b = medianCount(s, a, b, c, &swaps)
+	switch swaps {
+	case 0:
+		return b, increasingHint
+	case maxSwaps:
+		return b, decreasingHint
+	default:
+		return b, unknownHint
+	}
+}
+
+// medianCount returns the index of the median of s[a], s[b] and s[c], adds the swaps of ordering
+// them to swaps
+func medianCount(s sequence, a int, b int, c int, swaps *int) int {
+	if s.less(b, a) {
+		*swaps++
+		a, b = b, a
+	}
+	if s.less(c, b) {
+		*swaps++
+		b, c = c, b
+	}
+	if s.less(b, a) {
+		*swaps++
+		a, b = b, a
+	}
+	return b
+}
+
+func reverseRange(s sequence, lo int, hi int) {
+	for i, j := lo, hi-1; i < j; i, j = i+1, j-1 {
+		s.swap(i, j)
+	}
+}
@@ -0,0 +1,91 @@
+package sort
+
+import (
+	"math/bits"
+	"testing"
+)
+
+// adversary is McIlroy's killer adversary for quicksort. Values are undecided, the gas, until
+// compared, it decides them on the fly to make the pivot as bad as possible.
+type adversary struct {
+	val       []int
+	gas       int
+	candidate int // likely the pivot
+	nsolid    int
+	compares  int
+}
+
+func newAdversary(n int) (*adversary, []interface{}) {
+	adv := &adversary{val: make([]int, n), gas: n, candidate: -1}
+	a := make([]interface{}, n)
+	for i := range a {
+		adv.val[i] = n
+		a[i] = i
+	}
+	return adv, a
+}
+
+func (adv *adversary) less(a interface{}, b interface{}) bool {
+	x, y := a.(int), b.(int)
+	adv.compares++
+	if adv.val[x] == adv.gas && adv.val[y] == adv.gas {
+		if x == adv.candidate {
+			adv.freeze(x)
+		} else {
+			adv.freeze(y)
+		}
+	}
+	if adv.val[x] == adv.gas {
+		adv.candidate = x
+	} else if adv.val[y] == adv.gas {
+		adv.candidate = y
+	}
+	return adv.val[x] < adv.val[y]
+}
+
+func (adv *adversary) freeze(x int) {
+	adv.val[x] = adv.nsolid
+	adv.nsolid++
+}
+
+func TestPdqSort_Adversary(t *testing.T) {
+	const n = 1 << 13
+	bound := 4 * n * bits.Len(n) // a few times of nlogn
+	for _, st := range []sorter{sorters[0], {name: "PdqSort", funcs: PdqSortFunc}} {
+		adv, a := newAdversary(n)
+		st.funcs(a, adv.less)
+		for i := 1; i < n; i++ {
+			if adv.val[a[i].(int)] < adv.val[a[i-1].(int)] {
+				t.Fatalf("%s: index %d is not sorted", st.name, i)
+			}
+		}
+		t.Logf("%s: %d compares", st.name, adv.compares)
+		if st.name == "PdqSort" && adv.compares > bound {
+			t.Fatalf("%s: expected at most %d compares, got %d", st.name, bound, adv.compares)
+		}
+	}
+}
+
+func TestPdqSort_Compares(t *testing.T) {
+	const n = 1 << 13
+	for _, p := range []string{"Sorted", "Reversed", "Equal"} {
+		var keys []int
+		for _, pt := range patterns {
+			if pt.name == p {
+				keys = pt.gen(nil, n)
+			}
+		}
+		a := make([]interface{}, n)
+		for i, k := range keys {
+			a[i] = k
+		}
+		compares := 0
+		PdqSortFunc(a, func(a interface{}, b interface{}) bool {
+			compares++
+			return a.(int) < b.(int)
+		})
+		if compares > 4*n { // linear
+			t.Fatalf("%s: expected at most %d compares, got %d", p, 4*n, compares)
+		}
+	}
+}
@@ -1 +1,118 @@
 package sort
+
+import "github.com/joexzh/dsa"
+
+// ranges shorter than insertionCutoff are sorted by insertion sort
+const insertionCutoff = 12
+
+// QuickSort sorts a in ascending order, not stable.
+// The pivot is the median of three samples at the quartiles, or the median of three such medians for
+// long ranges, and the range is partitioned into three parts, less than, equal to and greater than
+// the pivot, so duplicates are not sorted again.
+// o(nlogn) expected, o(n^2) in the worst case, o(logn) extra space.
+func QuickSort(a []dsa.Item) {
+	quickSort(items(a), 0, len(a))
+}
+
+// QuickSortFunc sorts a in the order of less, by ItemLess if nil, see QuickSort
+func QuickSortFunc(a []interface{}, less Less) {
+	s := newValues(a, less)
+	quickSort(s, 0, s.Len())
+}
+
+// quickSort sorts s[lo, hi), recurses into the shorter part and loops on the longer one,
+// so the stack depth is o(logn)
+func quickSort(s sequence, lo int, hi int) {
+	for hi-lo >= insertionCutoff {
+		lt, gt := partition3(s, lo, hi, choosePivot(s, lo, hi))
+		if lt-lo < hi-gt {
+			quickSort(s, lo, lt)
+			lo = gt
+		} else {
+			quickSort(s, gt, hi)
+			hi = lt
+		}
+	}
+	insertionSort(s, lo, hi)
+}
+
+// partition3 partitions s[lo, hi) by the value at p into [lo, lt) less than it, [lt, gt) equal to it
+// and [gt, hi) greater than it, returns lt and gt.
+// It's the Bentley-McIlroy partition, i and j scan from both ends like Hoare's, the equal ones met
+// are kept at both ends, and swapped to the middle at last, so sorted inputs are not disturbed.
+func partition3(s sequence, lo int, hi int, p int) (int, int) {
+	s.swap(lo, p)
+	v := s.at(lo)
+	last := hi - 1
+	i, j := lo, hi
+	l, r := lo, hi // the equal ones are in [lo, l] and [r, hi)
+	for {
+		for i++; i < last && s.lessValue(s.at(i), v); i++ {
+		}
+		for j--; j > lo && s.lessValue(v, s.at(j)); j-- {
+		}
+		if i == j && equal(s, s.at(i), v) {
+			l++
+			s.swap(l, i)
+		}
+		if i >= j {
+			break
+		}
+		s.swap(i, j)
+		if equal(s, s.at(i), v) {
+			l++
+			s.swap(l, i)
+		}
+		if equal(s, s.at(j), v) {
+			r--
+			s.swap(r, j)
+		}
+	}
+	i = j + 1
+	for k := lo; k <= l; k++ {
+		s.swap(k, j)
+		j--
+	}
+	for k := last; k >= r; k-- {
+		s.swap(k, i)
+		i++
+	}
+	return j + 1, i
+}
+
+// equal returns true if neither a nor b is less than the other
+func equal(s sequence, a interface{}, b interface{}) bool {
+	return !s.lessValue(a, b) && !s.lessValue(b, a)
+}
+
+// ranges not shorter than nintherCutoff take the pivot from 9 samples
+const nintherCutoff = 40
+
+// choosePivot returns the index of the pivot of s[lo, hi).
+// The samples avoid both ends, which are often disturbed by the previous partition.
+func choosePivot(s sequence, lo int, hi int) int {
+	n := hi - lo
+	a, b, c := lo+n/4, lo+n/2, lo+n/4*3
+	if n >= nintherCutoff {
+		d := n / 8
+		a = medianOf3(s, a-d, a, a+d)
+		b = medianOf3(s, b-d, b, b+d)
+		c = medianOf3(s, c-d, c, c+d)
+	}
+	return medianOf3(s, a, b, c)
+}
+
+// medianOf3 returns the index of the median of s[a], s[b] and s[c]
+func medianOf3(s sequence, a int, b int, c int) int {
+	if s.less(b, a) {
+		a, b = b, a
+	}
+	// s[a] <= s[b]
+	if s.less(c, b) {
+		if s.less(c, a) {
+			return a
+		}
+		return c
+	}
+	return b
+}
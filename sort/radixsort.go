@@ -0,0 +1,184 @@
+package sort
+
+import "github.com/joexzh/dsa"
+
+const signBit = 1 << 63
+
+// Int64Key maps i to a uint64 in the same order by flipping the sign bit, for RadixSortFunc
+func Int64Key(i int64) uint64 {
+	return uint64(i) ^ signBit
+}
+
+// RadixSortInt64 sorts a in ascending order by LSD radix sort, negatives are ordered by the sign bit
+// flipped, see RadixSortFunc
+func RadixSortInt64(a []dsa.Int64) {
+	keys := make([]uint64, len(a))
+	for i, v := range a {
+		keys[i] = Int64Key(int64(v))
+	}
+	lsdRadixSort(keys, nil)
+	for i, k := range keys {
+		a[i] = dsa.Int64(int64(k ^ signBit))
+	}
+}
+
+// RadixSortUint64 sorts a in ascending order by LSD radix sort, see RadixSortFunc
+func RadixSortUint64(a []dsa.Uint64) {
+	keys := make([]uint64, len(a))
+	for i, v := range a {
+		keys[i] = uint64(v)
+	}
+	lsdRadixSort(keys, nil)
+	for i, k := range keys {
+		a[i] = dsa.Uint64(k)
+	}
+}
+
+// RadixSortFunc sorts a stably by the uint64 keys of its values, by LSD radix sort.
+// Each pass distributes by a byte of the keys from the lowest one, and the passes where all keys
+// have the same byte are skipped. o(n) of 8 passes at most, o(n) extra space.
+func RadixSortFunc(a []interface{}, key func(v interface{}) uint64) {
+	keys := make([]uint64, len(a))
+	for i, v := range a {
+		keys[i] = key(v)
+	}
+	lsdRadixSort(keys, a)
+}
+
+// lsdRadixSort sorts keys stably, and vals along with them if not nil
+func lsdRadixSort(keys []uint64, vals []interface{}) {
+	n := len(keys)
+	if n < 2 {
+		return
+	}
+	srcK, dstK := keys, make([]uint64, n)
+	var srcV, dstV []interface{}
+	if vals != nil {
+		srcV, dstV = vals, make([]interface{}, n)
+	}
+	for shift := uint(0); shift < 64; shift += 8 {
+		var count [256]int
+		for _, k := range srcK {
+			count[byte(k>>shift)]++
+		}
+		if count[byte(srcK[0]>>shift)] == n { // all the same byte
+			continue
+		}
+		sum := 0
+		for b, c := range count { // to the start of each byte
+			count[b] = sum
+			sum += c
+		}
+		for i, k := range srcK {
+			b := byte(k >> shift)
+			dstK[count[b]] = k
+			if vals != nil {
+				dstV[count[b]] = srcV[i]
+			}
+			count[b]++
+		}
+		srcK, dstK = dstK, srcK
+		srcV, dstV = dstV, srcV
+	}
+	if &srcK[0] != &keys[0] { // odd passes, the result is in the buffer
+		copy(keys, srcK)
+		copy(vals, srcV)
+	}
+}
+
+// ranges shorter than msdCutoff are sorted by insertion sort on the rest of the strings
+const msdCutoff = 16
+
+// RadixSortString sorts a in ascending order by MSD radix sort, see RadixSortStringFunc
+func RadixSortString(a []dsa.String) {
+	keys := make([]string, len(a))
+	for i, v := range a {
+		keys[i] = string(v)
+	}
+	m := newMSDSorter(keys, nil)
+	m.sort(0, len(keys), 0)
+	for i, k := range keys {
+		a[i] = dsa.String(k)
+	}
+}
+
+// RadixSortStringFunc sorts a stably by the string keys of its values, by MSD radix sort.
+// It distributes the strings by the byte at depth d, the ended ones go first, then sorts each
+// bucket by the next byte, so only the distinguishing prefixes are read.
+// o(n+total length of the distinguishing prefixes) plus o(256) of each bucket, o(n) extra space.
+func RadixSortStringFunc(a []interface{}, key func(v interface{}) string) {
+	keys := make([]string, len(a))
+	for i, v := range a {
+		keys[i] = key(v)
+	}
+	m := newMSDSorter(keys, a)
+	m.sort(0, len(keys), 0)
+}
+
+type msdSorter struct {
+	keys []string
+	vals []interface{} // nil if sorting keys only
+	auxK []string
+	auxV []interface{}
+}
+
+func newMSDSorter(keys []string, vals []interface{}) *msdSorter {
+	m := &msdSorter{keys: keys, vals: vals, auxK: make([]string, len(keys))}
+	if vals != nil {
+		m.auxV = make([]interface{}, len(vals))
+	}
+	return m
+}
+
+// sort keys[lo, hi) whose first d bytes are equal
+func (m *msdSorter) sort(lo int, hi int, d int) {
+	if hi-lo < msdCutoff {
+		m.insertionSort(lo, hi, d)
+		return
+	}
+	var count [256 + 2]int // bucket 0 for the ended strings, c+1 for byte c, shifted by one to count
+	for i := lo; i < hi; i++ {
+		count[charAt(m.keys[i], d)+2]++
+	}
+	for r := 0; r < 256+1; r++ {
+		count[r+1] += count[r]
+	}
+	for i := lo; i < hi; i++ {
+		c := charAt(m.keys[i], d) + 1
+		m.auxK[count[c]] = m.keys[i]
+		if m.vals != nil {
+			m.auxV[count[c]] = m.vals[i]
+		}
+		count[c]++
+	}
+	copy(m.keys[lo:hi], m.auxK[:hi-lo])
+	if m.vals != nil {
+		copy(m.vals[lo:hi], m.auxV[:hi-lo])
+	}
+	// count[c] is the start of bucket c+1 now, the ended strings in [0, count[0]) are done
+	for r := 0; r < 256; r++ {
+		if count[r+1]-count[r] > 1 {
+			m.sort(lo+count[r], lo+count[r+1], d+1)
+		}
+	}
+}
+
+// insertionSort sorts keys[lo, hi) by their bytes from d, stable
+func (m *msdSorter) insertionSort(lo int, hi int, d int) {
+	for i := lo + 1; i < hi; i++ {
+		for j := i; j > lo && m.keys[j][d:] < m.keys[j-1][d:]; j-- {
+			m.keys[j], m.keys[j-1] = m.keys[j-1], m.keys[j]
+			if m.vals != nil {
+				m.vals[j], m.vals[j-1] = m.vals[j-1], m.vals[j]
+			}
+		}
+	}
+}
+
+// charAt returns the byte of s at d, -1 if s is shorter
+func charAt(s string, d int) int {
+	if d < len(s) {
+		return int(s[d])
+	}
+	return -1
+}
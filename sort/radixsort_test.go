@@ -0,0 +1,175 @@
+package sort
+
+import (
+	"fmt"
+	"github.com/joexzh/dsa"
+	"math"
+	"math/rand"
+	stdsort "sort"
+	"strings"
+	"testing"
+)
+
+func randomInt64s(r *rand.Rand, n int) []dsa.Int64 {
+	a := make([]dsa.Int64, n)
+	for i := range a {
+		switch r.Intn(4) {
+		case 0:
+			a[i] = dsa.Int64(r.Int63())
+		case 1:
+			a[i] = -dsa.Int64(r.Int63())
+		default:
+			a[i] = dsa.Int64(r.Intn(2000) - 1000)
+		}
+	}
+	if n > 2 {
+		a[0], a[1] = math.MinInt64, math.MaxInt64
+	}
+	return a
+}
+
+func TestRadixSortInt64(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 3, 100, 10000} {
+		a := randomInt64s(r, n)
+		expected := append([]dsa.Int64(nil), a...)
+		stdsort.Slice(expected, func(i, j int) bool { return expected[i] < expected[j] })
+		RadixSortInt64(a)
+		for i := range a {
+			if a[i] != expected[i] {
+				t.Fatalf("n %d: index %d expected %d, got %d", n, i, expected[i], a[i])
+			}
+		}
+	}
+}
+
+func TestRadixSortUint64(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a := make([]dsa.Uint64, 10000)
+	for i := range a {
+		a[i] = dsa.Uint64(r.Uint64() >> uint(r.Intn(64)))
+	}
+	a[0] = math.MaxUint64
+	RadixSortUint64(a)
+	for i := 1; i < len(a); i++ {
+		if a[i] < a[i-1] {
+			t.Fatalf("index %d %d is less than %d", i, a[i], a[i-1])
+		}
+	}
+}
+
+func TestRadixSortFunc(t *testing.T) {
+	for _, p := range patterns {
+		keys := p.gen(rand.New(rand.NewSource(1)), 5000)
+		for i := range keys {
+			keys[i] -= 2500 // negatives
+		}
+		testSorter(t, sorter{
+			name: "RadixSortFunc",
+			items: func(a []dsa.Item) {
+				vals := make([]interface{}, len(a))
+				for i, v := range a {
+					vals[i] = v
+				}
+				RadixSortFunc(vals, func(v interface{}) uint64 { return Int64Key(int64(v.(dsa.Int64))) })
+				for i, v := range vals {
+					a[i] = v.(dsa.Item)
+				}
+			},
+			funcs: func(a []interface{}, less Less) {
+				RadixSortFunc(a, func(v interface{}) uint64 { return Int64Key(int64(v.(record).k)) })
+			},
+			stable: true,
+		}, p.name, keys)
+	}
+}
+
+func randomStrings(r *rand.Rand, n int) []string {
+	prefixes := []string{"", "a", "ab", "abc", "abcdefghijklmnop", "b", "\xff"}
+	a := make([]string, n)
+	for i := range a {
+		a[i] = prefixes[r.Intn(len(prefixes))] + strings.Repeat("x", r.Intn(3)) + fmt.Sprint(r.Intn(n) + 100)[:r.Intn(4)]
+	}
+	return a
+}
+
+func TestRadixSortString(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 15, 16, 17, 1000, 20000} {
+		strs := randomStrings(r, n)
+		a := make([]dsa.String, n)
+		recs := make([]interface{}, n)
+		for i, s := range strs {
+			a[i] = dsa.String(s)
+			recs[i] = [2]interface{}{s, i}
+		}
+		expected := append([]string(nil), strs...)
+		stdsort.Strings(expected)
+
+		RadixSortString(a)
+		for i := range a {
+			if string(a[i]) != expected[i] {
+				t.Fatalf("n %d: index %d expected %q, got %q", n, i, expected[i], a[i])
+			}
+		}
+		RadixSortStringFunc(recs, func(v interface{}) string { return v.([2]interface{})[0].(string) })
+		for i, v := range recs {
+			rec := v.([2]interface{})
+			if rec[0] != expected[i] {
+				t.Fatalf("n %d: index %d expected %q, got %q", n, i, expected[i], rec[0])
+			}
+			if i > 0 && rec[0] == recs[i-1].([2]interface{})[0] && rec[1].(int) < recs[i-1].([2]interface{})[1].(int) {
+				t.Fatalf("n %d: index %d of %q is not stable", n, i, rec[0])
+			}
+		}
+	}
+}
+
+func BenchmarkRadixSortInt64(b *testing.B) {
+	src := randomInt64s(rand.New(rand.NewSource(1)), benchSize)
+	a := make([]dsa.Int64, benchSize)
+	for i := 0; i < b.N; i++ {
+		copy(a, src)
+		RadixSortInt64(a)
+	}
+}
+
+func BenchmarkRadixSortString(b *testing.B) {
+	strs := randomStrings(rand.New(rand.NewSource(1)), benchSize)
+	src := make([]dsa.String, benchSize)
+	for i, s := range strs {
+		src[i] = dsa.String(s)
+	}
+	a := make([]dsa.String, benchSize)
+	for i := 0; i < b.N; i++ {
+		copy(a, src)
+		RadixSortString(a)
+	}
+}
+
+// the comparison sorts on the same inputs as the radix sorts
+func BenchmarkPdqSort_Int64(b *testing.B) {
+	ints := randomInt64s(rand.New(rand.NewSource(1)), benchSize)
+	src := make([]dsa.Item, benchSize)
+	for i, v := range ints {
+		src[i] = v
+	}
+	a := make([]dsa.Item, benchSize)
+	for i := 0; i < b.N; i++ {
+		copy(a, src)
+		PdqSort(a)
+	}
+}
+
+func BenchmarkPdqSort_String(b *testing.B) {
+	strs := randomStrings(rand.New(rand.NewSource(1)), benchSize)
+	src := make([]dsa.Item, benchSize)
+	for i, s := range strs {
+		src[i] = dsa.String(s)
+	}
+	a := make([]dsa.Item, benchSize)
+	for i := 0; i < b.N; i++ {
+		copy(a, src)
+		PdqSort(a)
+	}
+}
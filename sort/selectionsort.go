@@ -1 +1,31 @@
 package sort
+
+import "github.com/joexzh/dsa"
+
+// SelectionSort sorts a in ascending order in place, not stable.
+// Each pass selects the min of the unsorted part and swaps it to the front, at most n-1 swaps.
+// o(n^2) comparisons, only for short slices or expensive moves.
+func SelectionSort(a []dsa.Item) {
+	selectionSort(items(a), 0, len(a))
+}
+
+// SelectionSortFunc sorts a in the order of less in place, by ItemLess if nil, see SelectionSort
+func SelectionSortFunc(a []interface{}, less Less) {
+	s := newValues(a, less)
+	selectionSort(s, 0, s.Len())
+}
+
+// selectionSort sorts s[lo, hi)
+func selectionSort(s sequence, lo int, hi int) {
+	for i := lo; i < hi-1; i++ {
+		m := i
+		for j := i + 1; j < hi; j++ {
+			if s.less(j, m) {
+				m = j
+			}
+		}
+		if m != i {
+			s.swap(i, m)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package sort
+
+import "github.com/joexzh/dsa"
+
+// Less reports whether a goes before b, used by the Func variants of the sorts
+type Less func(a interface{}, b interface{}) bool
+
+// ItemLess compares elements as dsa.Item
+func ItemLess(a interface{}, b interface{}) bool {
+	return a.(dsa.Item).Less(b.(dsa.Item))
+}
+
+// sequence is the slice being sorted, so every algorithm is written once for both []dsa.Item and
+// []interface{}
+type sequence interface {
+	Len() int
+	at(i int) interface{}
+	set(i int, v interface{})
+	swap(i int, j int)
+	less(i int, j int) bool
+	lessValue(a interface{}, b interface{}) bool
+}
+
+type items []dsa.Item
+
+func (s items) Len() int {
+	return len(s)
+}
+
+func (s items) at(i int) interface{} {
+	return s[i]
+}
+
+func (s items) set(i int, v interface{}) {
+	s[i] = v.(dsa.Item)
+}
+
+func (s items) swap(i int, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s items) less(i int, j int) bool {
+	return s[i].Less(s[j])
+}
+
+func (s items) lessValue(a interface{}, b interface{}) bool {
+	return a.(dsa.Item).Less(b.(dsa.Item))
+}
+
+type values struct {
+	a  []interface{}
+	lt Less
+}
+
+func newValues(a []interface{}, less Less) values {
+	if less == nil {
+		less = ItemLess
+	}
+	return values{a, less}
+}
+
+func (s values) Len() int {
+	return len(s.a)
+}
+
+func (s values) at(i int) interface{} {
+	return s.a[i]
+}
+
+func (s values) set(i int, v interface{}) {
+	s.a[i] = v
+}
+
+func (s values) swap(i int, j int) {
+	s.a[i], s.a[j] = s.a[j], s.a[i]
+}
+
+func (s values) less(i int, j int) bool {
+	return s.lt(s.a[i], s.a[j])
+}
+
+func (s values) lessValue(a interface{}, b interface{}) bool {
+	return s.lt(a, b)
+}
+
+// IsSorted returns true if a is in ascending order
+func IsSorted(a []dsa.Item) bool {
+	return isSorted(items(a))
+}
+
+// IsSortedFunc returns true if a is in the order of less, by ItemLess if nil
+func IsSortedFunc(a []interface{}, less Less) bool {
+	return isSorted(newValues(a, less))
+}
+
+func isSorted(s sequence) bool {
+	for i := s.Len() - 1; i > 0; i-- {
+		if s.less(i, i-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// insertionSort sorts s[lo, hi) stably, fast for short or nearly sorted ranges
+func insertionSort(s sequence, lo int, hi int) {
+	for i := lo + 1; i < hi; i++ {
+		v := s.at(i)
+		j := i
+		for ; j > lo && s.lessValue(v, s.at(j-1)); j-- {
+			s.set(j, s.at(j-1))
+		}
+		s.set(j, v)
+	}
+}
+
+// shorter slices are sorted by PdqSort, the non-comparison sorts don't pay off
+const radixCutoff = 64
+
+// Sort sorts a in ascending order by the best algorithm for the type of its items, not stable.
+// For dsa.Int64, counting sort if the range is not wider than the length, otherwise LSD radix sort,
+// so is dsa.Uint64, and MSD radix sort for dsa.String. Mixed or other types are sorted by PdqSort.
+func Sort(a []dsa.Item) {
+	if len(a) < radixCutoff {
+		PdqSort(a)
+		return
+	}
+	switch a[0].(type) {
+	case dsa.Int64:
+		ints := make([]dsa.Int64, len(a))
+		for i, v := range a {
+			x, ok := v.(dsa.Int64)
+			if !ok { // mixed types
+				PdqSort(a)
+				return
+			}
+			ints[i] = x
+		}
+		if inRange(ints, len(a)) {
+			CountingSortInt64(ints)
+		} else {
+			RadixSortInt64(ints)
+		}
+		for i, v := range ints {
+			a[i] = v
+		}
+	case dsa.Uint64:
+		uints := make([]dsa.Uint64, len(a))
+		for i, v := range a {
+			x, ok := v.(dsa.Uint64)
+			if !ok { // mixed types
+				PdqSort(a)
+				return
+			}
+			uints[i] = x
+		}
+		RadixSortUint64(uints)
+		for i, v := range uints {
+			a[i] = v
+		}
+	case dsa.String:
+		strs := make([]dsa.String, len(a))
+		for i, v := range a {
+			x, ok := v.(dsa.String)
+			if !ok { // mixed types
+				PdqSort(a)
+				return
+			}
+			strs[i] = x
+		}
+		RadixSortString(strs)
+		for i, v := range strs {
+			a[i] = v
+		}
+	default:
+		PdqSort(a)
+	}
+}
+
+// inRange returns true if max-min of a is less than k
+func inRange(a []dsa.Int64, k int) bool {
+	min, max := a[0], a[0]
+	for _, v := range a {
+		if v < min {
+			min = v
+		} else if v > max {
+			max = v
+		}
+	}
+	return uint64(max)-uint64(min) < uint64(k)
+}
@@ -0,0 +1,222 @@
+package sort
+
+import (
+	"fmt"
+	"github.com/joexzh/dsa"
+	"math/rand"
+	stdsort "sort"
+	"testing"
+)
+
+// sorter is an algorithm under the shared tests and benchmarks
+type sorter struct {
+	name   string
+	items  func(a []dsa.Item)
+	funcs  func(a []interface{}, less Less)
+	stable bool
+	slow   bool // o(n^2), only run on short inputs
+}
+
+var sorters = []sorter{
+	{"QuickSort", QuickSort, QuickSortFunc, false, false},
+	{"MergeSort", MergeSort, MergeSortFunc, true, false},
+	{"MergeSortBottomUp", MergeSortBottomUp, MergeSortBottomUpFunc, true, false},
+	{"HeapSort", HeapSort, HeapSortFunc, false, false},
+	{"SelectionSort", SelectionSort, SelectionSortFunc, false, true},
+	{"PdqSort", PdqSort, PdqSortFunc, false, false},
+	{"TimSort", TimSort, TimSortFunc, true, false},
+	{"ParallelMergeSort", ParallelMergeSort, ParallelMergeSortFunc, true, false},
+	{"ParallelQuickSort", ParallelQuickSort, ParallelQuickSortFunc, false, false},
+}
+
+// patterns generate keys of n, the shapes that break naive algorithms
+var patterns = []struct {
+	name string
+	gen  func(r *rand.Rand, n int) []int
+}{
+	{"Random", func(r *rand.Rand, n int) []int {
+		return genInts(n, func(i int) int { return r.Intn(n + 1) })
+	}},
+	{"Sorted", func(r *rand.Rand, n int) []int {
+		return genInts(n, func(i int) int { return i })
+	}},
+	{"Reversed", func(r *rand.Rand, n int) []int {
+		return genInts(n, func(i int) int { return n - i })
+	}},
+	{"Equal", func(r *rand.Rand, n int) []int {
+		return genInts(n, func(i int) int { return 7 })
+	}},
+	{"FewUnique", func(r *rand.Rand, n int) []int {
+		return genInts(n, func(i int) int { return r.Intn(8) })
+	}},
+	{"OrganPipe", func(r *rand.Rand, n int) []int {
+		return genInts(n, func(i int) int { return minInt(i, n-i) })
+	}},
+	{"Sawtooth", func(r *rand.Rand, n int) []int {
+		return genInts(n, func(i int) int { return i % 97 })
+	}},
+	{"NearlySorted", func(r *rand.Rand, n int) []int {
+		a := genInts(n, func(i int) int { return i })
+		for k := 0; k < n/100+1 && n > 0; k++ {
+			i, j := r.Intn(n), r.Intn(n)
+			a[i], a[j] = a[j], a[i]
+		}
+		return a
+	}},
+}
+
+func genInts(n int, f func(i int) int) []int {
+	a := make([]int, n)
+	for i := range a {
+		a[i] = f(i)
+	}
+	return a
+}
+
+// record is sorted by k only, i is the original index to check stability
+type record struct {
+	k int
+	i int
+}
+
+func recordLess(a interface{}, b interface{}) bool {
+	return a.(record).k < b.(record).k
+}
+
+// testSorter sorts keys as records by funcs and as dsa.Int64 by items, compares them with the
+// standard library
+func testSorter(t *testing.T, st sorter, pattern string, keys []int) {
+	expected := make([]record, len(keys))
+	recs := make([]interface{}, len(keys))
+	its := make([]dsa.Item, len(keys))
+	for i, k := range keys {
+		expected[i] = record{k, i}
+		recs[i] = record{k, i}
+		its[i] = dsa.Int64(k)
+	}
+	stdsort.SliceStable(expected, func(i, j int) bool { return expected[i].k < expected[j].k })
+
+	st.funcs(recs, recordLess)
+	for i, r := range recs {
+		if r.(record).k != expected[i].k || st.stable && r != expected[i] {
+			t.Fatalf("%s %s of %d: index %d expected %v, got %v", st.name, pattern, len(keys), i, expected[i], r)
+		}
+	}
+	st.items(its)
+	for i, it := range its {
+		if it != dsa.Int64(expected[i].k) {
+			t.Fatalf("%s %s of %d: item %d expected %v, got %v", st.name, pattern, len(keys), i, expected[i].k, it)
+		}
+	}
+}
+
+func TestSorters(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, st := range sorters {
+		for _, p := range patterns {
+			for _, n := range []int{0, 1, 2, 3, 5, 11, 12, 13, 50, 100, 1000, 10000} {
+				if st.slow && n > 1000 {
+					continue
+				}
+				testSorter(t, st, p.name, p.gen(r, n))
+			}
+		}
+	}
+}
+
+func TestSorters_String(t *testing.T) {
+	for _, st := range sorters {
+		a := make([]dsa.Item, 1000)
+		vals := make([]interface{}, len(a))
+		for i := range a {
+			a[i] = dsa.String(fmt.Sprint(rand.Intn(500)))
+			vals[i] = a[i]
+		}
+		st.items(a)
+		if !IsSorted(a) {
+			t.Fatalf("%s: expected strings sorted", st.name)
+		}
+		st.funcs(vals, nil) // by ItemLess
+		if !IsSortedFunc(vals, nil) {
+			t.Fatalf("%s: expected strings sorted by ItemLess", st.name)
+		}
+	}
+}
+
+func TestIsSorted(t *testing.T) {
+	if !IsSorted(nil) || !IsSorted([]dsa.Item{dsa.Int64(1), dsa.Int64(1), dsa.Int64(2)}) {
+		t.Fatal("expected sorted")
+	}
+	if IsSorted([]dsa.Item{dsa.Int64(1), dsa.Int64(2), dsa.Int64(1)}) {
+		t.Fatal("expected not sorted")
+	}
+}
+
+const benchSize = 1 << 16
+
+// benchmarkSort sorts dsa.Int64 items of each pattern by sort
+func benchmarkSort(b *testing.B, name string, n int, sort func(a []dsa.Item)) {
+	for _, p := range patterns {
+		keys := p.gen(rand.New(rand.NewSource(1)), n)
+		src := make([]dsa.Item, n)
+		for i, k := range keys {
+			src[i] = dsa.Int64(k)
+		}
+		a := make([]dsa.Item, n)
+		b.Run(fmt.Sprintf("%s/%s/%d", name, p.name, n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(a, src)
+				sort(a)
+			}
+		})
+	}
+}
+
+func BenchmarkSorters(b *testing.B) {
+	for _, st := range sorters {
+		n := benchSize
+		if st.slow {
+			n = 1 << 10
+		}
+		benchmarkSort(b, st.name, n, st.items)
+	}
+}
+
+func BenchmarkStd(b *testing.B) {
+	benchmarkSort(b, "Sort", benchSize, func(a []dsa.Item) {
+		stdsort.Slice(a, func(i, j int) bool { return a[i].Less(a[j]) })
+	})
+	benchmarkSort(b, "SliceStable", benchSize, func(a []dsa.Item) {
+		stdsort.SliceStable(a, func(i, j int) bool { return a[i].Less(a[j]) })
+	})
+}
+
+func TestSort(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	gens := map[string]func(i int) dsa.Item{
+		"Int64":      func(i int) dsa.Item { return dsa.Int64(r.Int63() - r.Int63()) },
+		"SmallInt64": func(i int) dsa.Item { return dsa.Int64(r.Intn(100) - 50) },
+		"Uint64":     func(i int) dsa.Item { return dsa.Uint64(r.Uint64()) },
+		"String":     func(i int) dsa.Item { return dsa.String(fmt.Sprint(r.Intn(1000))) },
+		"Other":      func(i int) dsa.Item { return otherItem(r.Intn(1000)) },
+	}
+	for name, gen := range gens {
+		for _, n := range []int{0, 10, 63, 64, 1000} {
+			a := make([]dsa.Item, n)
+			for i := range a {
+				a[i] = gen(i)
+			}
+			Sort(a)
+			if !IsSorted(a) {
+				t.Fatalf("%s of %d: expected sorted", name, n)
+			}
+		}
+	}
+}
+
+// otherItem is not a key type known by Sort
+type otherItem int
+
+func (o otherItem) Less(than dsa.Item) bool {
+	return o < than.(otherItem)
+}
@@ -0,0 +1,477 @@
+package sort
+
+import "github.com/joexzh/dsa"
+
+const (
+	minMerge   = 32 // shorter slices are sorted by binary insertion sort only, without merging
+	minGallop  = 7  // the initial count of wins in a row to enter the galloping mode
+	timSortMax = 85 // enough runs for 2^64 elements, since the run lengths grow like fibonacci
+)
+
+// TimSort sorts a in ascending order, stable.
+// It finds the natural runs, reverses the strictly descending ones, extends the short ones to the
+// min run length by binary insertion sort, and merges them on a stack keeping the run lengths
+// balanced. When one run keeps winning, merging gallops by exponential search to move a block at
+// once. o(n) for sorted or reversed inputs, o(nlogn) in the worst case, o(n) extra space.
+func TimSort(a []dsa.Item) {
+	timSort(items(a))
+}
+
+// TimSortFunc sorts a in the order of less stably, by ItemLess if nil, see TimSort
+func TimSortFunc(a []interface{}, less Less) {
+	timSort(newValues(a, less))
+}
+
+type run struct {
+	base int
+	n    int
+}
+
+type timSorter struct {
+	s         sequence
+	tmp       []interface{}
+	minGallop int
+	runs      []run
+}
+
+func timSort(s sequence) {
+	n := s.Len()
+	if n < 2 {
+		return
+	}
+	if n < minMerge {
+		binaryInsertionSort(s, 0, n, countRunAndMakeAscending(s, 0, n))
+		return
+	}
+	ts := &timSorter{s: s, minGallop: minGallop, runs: make([]run, 0, timSortMax)}
+	minRun := minRunLength(n)
+	for lo := 0; lo < n; {
+		r := countRunAndMakeAscending(s, lo, n)
+		if r < minRun {
+			force := minInt(minRun, n-lo)
+			binaryInsertionSort(s, lo, lo+force, lo+r)
+			r = force
+		}
+		ts.runs = append(ts.runs, run{lo, r})
+		ts.mergeCollapse()
+		lo += r
+	}
+	ts.mergeForceCollapse()
+}
+
+// minRunLength returns a length in [minMerge/2, minMerge], so that n/minRun is a power of 2 or a
+// bit less, then the runs are merged in balance
+func minRunLength(n int) int {
+	r := 0 // 1 if any bit shifted off is 1
+	for n >= minMerge {
+		r |= n & 1
+		n >>= 1
+	}
+	return n + r
+}
+
+// countRunAndMakeAscending returns the length of the run starting at lo, which is reversed if
+// it's strictly descending, so the equal ones keep their order
+func countRunAndMakeAscending(s sequence, lo int, hi int) int {
+	i := lo + 1
+	if i == hi {
+		return 1
+	}
+	if s.less(i, lo) {
+		for i++; i < hi && s.less(i, i-1); i++ {
+		}
+		reverseRange(s, lo, i)
+	} else {
+		for i++; i < hi && !s.less(i, i-1); i++ {
+		}
+	}
+	return i - lo
+}
+
+// binaryInsertionSort sorts s[lo, hi) whose prefix s[lo, start) is sorted, the position to insert is
+// found by binary search after the equal ones, so it's stable
+func binaryInsertionSort(s sequence, lo int, hi int, start int) {
+	if start == lo {
+		start++
+	}
+	for ; start < hi; start++ {
+		v := s.at(start)
+		l, r := lo, start
+		for l < r {
+			m := int(uint(l+r) >> 1)
+			if s.lessValue(v, s.at(m)) {
+				r = m
+			} else {
+				l = m + 1
+			}
+		}
+		for j := start; j > l; j-- {
+			s.set(j, s.at(j-1))
+		}
+		s.set(l, v)
+	}
+}
+
+// mergeCollapse merges the runs on the stack until, for the top ones x, y, z and w,
+// w > x+y, x > y+z and y > z. Checking w too is the fix to the original invariant which could break.
+func (ts *timSorter) mergeCollapse() {
+	for len(ts.runs) > 1 {
+		rs := ts.runs
+		n := len(rs) - 2
+		if n > 0 && rs[n-1].n <= rs[n].n+rs[n+1].n || n > 1 && rs[n-2].n <= rs[n-1].n+rs[n].n {
+			if rs[n-1].n < rs[n+1].n {
+				n--
+			}
+		} else if rs[n].n > rs[n+1].n {
+			return
+		}
+		ts.mergeAt(n)
+	}
+}
+
+// mergeForceCollapse merges all runs on the stack into one
+func (ts *timSorter) mergeForceCollapse() {
+	for len(ts.runs) > 1 {
+		n := len(ts.runs) - 2
+		if n > 0 && ts.runs[n-1].n < ts.runs[n+1].n {
+			n--
+		}
+		ts.mergeAt(n)
+	}
+}
+
+// mergeAt merges the runs i and i+1 on the stack
+func (ts *timSorter) mergeAt(i int) {
+	s := ts.s
+	base1, n1 := ts.runs[i].base, ts.runs[i].n
+	base2, n2 := ts.runs[i+1].base, ts.runs[i+1].n
+	ts.runs[i].n = n1 + n2
+	ts.runs = append(ts.runs[:i+1], ts.runs[i+2:]...)
+
+	// the ones of run1 not greater than the first of run2 are in place already
+	k := gallopRight(s.at(base2), s, s.at, base1, n1, 0)
+	base1 += k
+	n1 -= k
+	if n1 == 0 {
+		return
+	}
+	// so are the ones of run2 not less than the last of run1
+	n2 = gallopLeft(s.at(base1+n1-1), s, s.at, base2, n2, n2-1)
+	if n2 == 0 {
+		return
+	}
+	if n1 <= n2 {
+		ts.mergeLo(base1, n1, base2, n2)
+	} else {
+		ts.mergeHi(base1, n1, base2, n2)
+	}
+}
+
+// gallopLeft returns k in [0, n], the leftmost position to insert key into the sorted at[base, base+n),
+// at[base+k-1] < key <= at[base+k]. It searches from hint by exponential steps, then binary search.
+func gallopLeft(key interface{}, s sequence, at func(i int) interface{}, base int, n int, hint int) int {
+	last, ofs := 0, 1
+	if s.lessValue(at(base+hint), key) { // gallop right until at[base+hint+last] < key <= at[base+hint+ofs]
+		maxOfs := n - hint
+		for ofs < maxOfs && s.lessValue(at(base+hint+ofs), key) {
+			last = ofs
+			ofs = ofs<<1 + 1
+		}
+		if ofs > maxOfs {
+			ofs = maxOfs
+		}
+		last += hint
+		ofs += hint
+	} else { // gallop left until at[base+hint-ofs] < key <= at[base+hint-last]
+		maxOfs := hint + 1
+		for ofs < maxOfs && !s.lessValue(at(base+hint-ofs), key) {
+			last = ofs
+			ofs = ofs<<1 + 1
+		}
+		if ofs > maxOfs {
+			ofs = maxOfs
+		}
+		last, ofs = hint-ofs, hint-last
+	}
+	// at[base+last] < key <= at[base+ofs]
+	for last++; last < ofs; {
+		m := last + (ofs-last)>>1
+		if s.lessValue(at(base+m), key) {
+			last = m + 1
+		} else {
+			ofs = m
+		}
+	}
+	return ofs
+}
+
+// gallopRight is like gallopLeft, but returns the rightmost position, at[base+k-1] <= key < at[base+k]
+func gallopRight(key interface{}, s sequence, at func(i int) interface{}, base int, n int, hint int) int {
+	last, ofs := 0, 1
+	if s.lessValue(key, at(base+hint)) { // gallop left until at[base+hint-ofs] <= key < at[base+hint-last]
+		maxOfs := hint + 1
+		for ofs < maxOfs && s.lessValue(key, at(base+hint-ofs)) {
+			last = ofs
+			ofs = ofs<<1 + 1
+		}
+		if ofs > maxOfs {
+			ofs = maxOfs
+		}
+		last, ofs = hint-ofs, hint-last
+	} else { // gallop right until at[base+hint+last] <= key < at[base+hint+ofs]
+		maxOfs := n - hint
+		for ofs < maxOfs && !s.lessValue(key, at(base+hint+ofs)) {
+			last = ofs
+			ofs = ofs<<1 + 1
+		}
+		if ofs > maxOfs {
+			ofs = maxOfs
+		}
+		last += hint
+		ofs += hint
+	}
+	// at[base+last] <= key < at[base+ofs]
+	for last++; last < ofs; {
+		m := last + (ofs-last)>>1
+		if s.lessValue(key, at(base+m)) {
+			ofs = m
+		} else {
+			last = m + 1
+		}
+	}
+	return ofs
+}
+
+func (ts *timSorter) ensureTmp(n int) []interface{} {
+	if len(ts.tmp) < n {
+		ts.tmp = make([]interface{}, maxInt(n, minInt(2*len(ts.tmp), ts.s.Len()/2)))
+	}
+	return ts.tmp
+}
+
+func (ts *timSorter) tmpAt(i int) interface{} {
+	return ts.tmp[i]
+}
+
+// mergeLo merges the adjacent runs s[base1, base1+n1) and s[base2, base2+n2) from the left,
+// n1 <= n2, run1 is moved to tmp. The first of run2 goes before run1 and the last of run1 goes
+// after run2, ensured by mergeAt.
+func (ts *timSorter) mergeLo(base1 int, n1 int, base2 int, n2 int) {
+	s := ts.s
+	tmp := ts.ensureTmp(n1)
+	for i := 0; i < n1; i++ {
+		tmp[i] = s.at(base1 + i)
+	}
+	c1, c2, dest := 0, base2, base1 // cursors of tmp, run2, and s
+	s.set(dest, s.at(c2))
+	dest++
+	c2++
+	if n2--; n2 == 0 {
+		copyFromTmp(s, tmp, c1, dest, n1)
+		return
+	}
+	if n1 == 1 {
+		copyWithin(s, c2, dest, n2)
+		s.set(dest+n2, tmp[c1])
+		return
+	}
+	mg := ts.minGallop
+outer:
+	for {
+		count1, count2 := 0, 0 // wins in a row
+		for (count1 | count2) < mg {
+			if s.lessValue(s.at(c2), tmp[c1]) {
+				s.set(dest, s.at(c2))
+				dest++
+				c2++
+				count2++
+				count1 = 0
+				if n2--; n2 == 0 {
+					break outer
+				}
+			} else {
+				s.set(dest, tmp[c1])
+				dest++
+				c1++
+				count1++
+				count2 = 0
+				if n1--; n1 == 1 {
+					break outer
+				}
+			}
+		}
+		// galloping, until neither run wins a block of minGallop
+		for {
+			count1 = gallopRight(s.at(c2), s, ts.tmpAt, c1, n1, 0)
+			if count1 != 0 {
+				copyFromTmp(s, tmp, c1, dest, count1)
+				dest += count1
+				c1 += count1
+				if n1 -= count1; n1 <= 1 {
+					break outer
+				}
+			}
+			s.set(dest, s.at(c2))
+			dest++
+			c2++
+			if n2--; n2 == 0 {
+				break outer
+			}
+			count2 = gallopLeft(tmp[c1], s, s.at, c2, n2, 0)
+			if count2 != 0 {
+				copyWithin(s, c2, dest, count2)
+				dest += count2
+				c2 += count2
+				if n2 -= count2; n2 == 0 {
+					break outer
+				}
+			}
+			s.set(dest, tmp[c1])
+			dest++
+			c1++
+			if n1--; n1 == 1 {
+				break outer
+			}
+			mg--
+			if count1 < minGallop && count2 < minGallop {
+				break
+			}
+		}
+		if mg < 0 {
+			mg = 0
+		}
+		mg += 2 // penalty for leaving the galloping mode
+	}
+	ts.minGallop = maxInt(mg, 1)
+	if n1 == 1 {
+		copyWithin(s, c2, dest, n2)
+		s.set(dest+n2, tmp[c1])
+	} else { // n1 is 0 only if less is inconsistent, nothing left then
+		copyFromTmp(s, tmp, c1, dest, n1)
+	}
+}
+
+// mergeHi is like mergeLo but merges from the right, n1 > n2, run2 is moved to tmp
+func (ts *timSorter) mergeHi(base1 int, n1 int, base2 int, n2 int) {
+	s := ts.s
+	tmp := ts.ensureTmp(n2)
+	for i := 0; i < n2; i++ {
+		tmp[i] = s.at(base2 + i)
+	}
+	c1, c2, dest := base1+n1-1, n2-1, base2+n2-1 // cursors of run1, tmp, and s
+	s.set(dest, s.at(c1))
+	dest--
+	c1--
+	if n1--; n1 == 0 {
+		copyFromTmp(s, tmp, 0, dest-(n2-1), n2)
+		return
+	}
+	if n2 == 1 {
+		dest -= n1
+		c1 -= n1
+		copyWithin(s, c1+1, dest+1, n1)
+		s.set(dest, tmp[c2])
+		return
+	}
+	mg := ts.minGallop
+outer:
+	for {
+		count1, count2 := 0, 0
+		for (count1 | count2) < mg {
+			if s.lessValue(tmp[c2], s.at(c1)) {
+				s.set(dest, s.at(c1))
+				dest--
+				c1--
+				count1++
+				count2 = 0
+				if n1--; n1 == 0 {
+					break outer
+				}
+			} else {
+				s.set(dest, tmp[c2])
+				dest--
+				c2--
+				count2++
+				count1 = 0
+				if n2--; n2 == 1 {
+					break outer
+				}
+			}
+		}
+		for {
+			count1 = n1 - gallopRight(tmp[c2], s, s.at, base1, n1, n1-1)
+			if count1 != 0 {
+				dest -= count1
+				c1 -= count1
+				copyWithin(s, c1+1, dest+1, count1)
+				if n1 -= count1; n1 == 0 {
+					break outer
+				}
+			}
+			s.set(dest, tmp[c2])
+			dest--
+			c2--
+			if n2--; n2 == 1 {
+				break outer
+			}
+			count2 = n2 - gallopLeft(s.at(c1), s, ts.tmpAt, 0, n2, n2-1)
+			if count2 != 0 {
+				dest -= count2
+				c2 -= count2
+				copyFromTmp(s, tmp, c2+1, dest+1, count2)
+				if n2 -= count2; n2 <= 1 {
+					break outer
+				}
+			}
+			s.set(dest, s.at(c1))
+			dest--
+			c1--
+			if n1--; n1 == 0 {
+				break outer
+			}
+			mg--
+			if count1 < minGallop && count2 < minGallop {
+				break
+			}
+		}
+		if mg < 0 {
+			mg = 0
+		}
+		mg += 2
+	}
+	ts.minGallop = maxInt(mg, 1)
+	if n2 == 1 {
+		dest -= n1
+		c1 -= n1
+		copyWithin(s, c1+1, dest+1, n1)
+		s.set(dest, tmp[c2])
+	} else { // n2 is 0 only if less is inconsistent
+		copyFromTmp(s, tmp, 0, dest-(n2-1), n2)
+	}
+}
+
+// copyWithin copies s[src, src+n) to s[dst, dst+n), they may overlap
+func copyWithin(s sequence, src int, dst int, n int) {
+	if dst < src {
+		for i := 0; i < n; i++ {
+			s.set(dst+i, s.at(src+i))
+		}
+	} else {
+		for i := n - 1; i >= 0; i-- {
+			s.set(dst+i, s.at(src+i))
+		}
+	}
+}
+
+func copyFromTmp(s sequence, tmp []interface{}, src int, dst int, n int) {
+	for i := 0; i < n; i++ {
+		s.set(dst+i, tmp[src+i])
+	}
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
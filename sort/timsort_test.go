@@ -0,0 +1,67 @@
+package sort
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// runsOf generates n keys of sorted and reversed runs of random lengths, duplicates across runs,
+// so merging gallops
+func runsOf(r *rand.Rand, n int, maxRun int) []int {
+	keys := make([]int, 0, n)
+	for len(keys) < n {
+		l := minInt(r.Intn(maxRun)+1, n-len(keys))
+		start, step := r.Intn(n), r.Intn(3)
+		if r.Intn(3) == 0 {
+			step = -step
+		}
+		for i := 0; i < l; i++ {
+			keys = append(keys, start+i*step)
+		}
+	}
+	return keys
+}
+
+func TestTimSort_Runs(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	st := sorter{name: "TimSort", items: TimSort, funcs: TimSortFunc, stable: true}
+	for _, maxRun := range []int{1, 10, 100, 1000, 10000} {
+		for _, n := range []int{31, 32, 33, 65, 1000, 50000} {
+			testSorter(t, st, "Runs", runsOf(r, n, maxRun))
+		}
+	}
+}
+
+func TestTimSort_Compares(t *testing.T) {
+	const n = 1 << 13
+	for _, p := range patterns {
+		if p.name != "Sorted" && p.name != "Reversed" {
+			continue
+		}
+		keys := p.gen(nil, n)
+		a := make([]interface{}, n)
+		for i, k := range keys {
+			a[i] = k
+		}
+		compares := 0
+		TimSortFunc(a, func(a interface{}, b interface{}) bool {
+			compares++
+			return a.(int) < b.(int)
+		})
+		if compares >= n {
+			t.Fatalf("%s: expected less than %d compares, got %d", p.name, n, compares)
+		}
+	}
+}
+
+func TestMinRunLength(t *testing.T) {
+	for n := minMerge; n < 1<<16; n += 97 {
+		m := minRunLength(n)
+		if m < minMerge/2 || m > minMerge {
+			t.Fatalf("min run of %d expected in [%d, %d], got %d", n, minMerge/2, minMerge, m)
+		}
+	}
+	if got := minRunLength(1 << 16); got != minMerge/2 {
+		t.Fatalf("min run of a power of 2 expected %d, got %d", minMerge/2, got)
+	}
+}
@@ -1 +1,167 @@
 package tree
+
+import (
+	"fmt"
+	"github.com/joexzh/dsa"
+	"github.com/joexzh/dsa/dict"
+)
+
+var _ dict.Dictionary = (*AVLTree)(nil)
+
+// AVLTree is a BST whose balance factor of every node is in [-1, 1]
+type AVLTree struct {
+	BST
+}
+
+func NewAVLTree() AVLTree {
+	return AVLTree{BST: NewBST()}
+}
+
+// Put inserts a key-value pair. If k already exists, replaces the value and returns false.
+func (t *AVLTree) Put(k dsa.Item, v interface{}) bool {
+	x, ok := t.insert(k, v)
+	if ok {
+		t.rebalanceAbove(x.parent)
+	}
+	return ok
+}
+
+// Remove the node of key k, returns false if not found
+func (t *AVLTree) Remove(k dsa.Item) bool {
+	xp := t.search(k)
+	if *xp == nil {
+		return false
+	}
+	t.removeAt(xp)
+	t.size--
+	t.rebalanceAbove(t.hot)
+	return true
+}
+
+// Join moves all entries of other into t, other becomes empty.
+// Every key of other must be bigger than keys of t, otherwise panic. o(logn).
+func (t *AVLTree) Join(other *AVLTree) {
+	if other.Empty() {
+		return
+	}
+	if !t.Empty() && !t.Last().entry.K.Less(other.First().entry.K) {
+		panic("AVLTree.Join: keys of other must be bigger than keys of t")
+	}
+
+	// take the smallest node of other as the middle one
+	m := other.First()
+	other.removeAt(other.fromParentTo(m))
+	other.size--
+	other.rebalanceAbove(other.hot)
+
+	t.root = t.join(t.root, m, other.root)
+	t.size += other.size + 1
+	other.root, other.size = nil, 0
+}
+
+// Split moves entries whose key is not less than k to a new tree and returns it,
+// t keeps the ones less than k. o(log^2 n), it joins the o(logn) subtrees along the path of k, and
+// each join walks up to the root of the joined tree, see join.
+func (t *AVLTree) Split(k dsa.Item) AVLTree {
+	l, r := t.split(t.root, k)
+	rt := NewAVLTree()
+	rt.augment = t.augment
+	rt.root = r
+	rt.size = sizeOf(r)
+	t.root = l
+	t.size -= rt.size
+	return rt
+}
+
+// Validate checks the BST properties, heights and balance factors
+func (t *AVLTree) Validate() error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+	var err error
+	t.root.TravPost(func(x *BinNode) {
+		if err == nil && !avlBalanced(x) {
+			err = fmt.Errorf("node %v is unbalanced, balance factor %d", x.entry, balanceFactor(x))
+		}
+	})
+	return err
+}
+
+// rebalanceAbove walks from g up to root, updates heights, and rotates at the unbalanced ones
+func (t *AVLTree) rebalanceAbove(g *BinNode) {
+	for ; g != nil; g = g.parent {
+		if !avlBalanced(g) {
+			g = t.rotateAt(g.tallerChild().tallerChild())
+		} else {
+			t.update(g)
+		}
+	}
+}
+
+// join joins the subtree l, node m and subtree r, in order of l < m < r,
+// and returns the root of the joined tree.
+// m goes down the taller one by |h(l)-h(r)| levels, but the heights stop changing soon above it,
+// the walk up to the root is for the subtree sizes and augmented values, which every ancestor of m
+// holds, so it's o(max(h(l), h(r))), not o(|h(l)-h(r)|) of the trees without them.
+func (t *AVLTree) join(l *BinNode, m *BinNode, r *BinNode) *BinNode {
+	m.parent = nil
+	if stature(l) <= stature(r)+1 && stature(r) <= stature(l)+1 { // m is balanced on top of them
+		attach(m, l, r)
+		t.update(m)
+		return m
+	}
+
+	s := NewAVLTree()
+	s.augment = t.augment
+	if stature(l) > stature(r) { // go down along the right branch of l until it's as short as r
+		s.root = l
+		p := l
+		for stature(p.rc) > stature(r)+1 {
+			p = p.rc
+		}
+		attach(m, p.rc, r)
+		p.rc, m.parent = m, p
+	} else {
+		s.root = r
+		p := r
+		for stature(p.lc) > stature(l)+1 {
+			p = p.lc
+		}
+		attach(m, l, p.lc)
+		p.lc, m.parent = m, p
+	}
+	s.update(m)
+	s.rebalanceAbove(m.parent)
+	return s.root
+}
+
+// split splits the subtree x into l < k and r >= k, and returns their roots
+func (t *AVLTree) split(x *BinNode, k dsa.Item) (l *BinNode, r *BinNode) {
+	if x == nil {
+		return nil, nil
+	}
+	lc, rc := x.lc, x.rc
+	if lc != nil {
+		lc.parent = nil
+	}
+	if rc != nil {
+		rc.parent = nil
+	}
+	x.lc, x.rc, x.parent = nil, nil, nil
+
+	if x.entry.K.Less(k) {
+		rl, rr := t.split(rc, k)
+		return t.join(lc, x, rl), rr
+	}
+	ll, lr := t.split(lc, k)
+	return ll, t.join(lr, x, rc)
+}
+
+func balanceFactor(x *BinNode) int {
+	return stature(x.lc) - stature(x.rc)
+}
+
+func avlBalanced(x *BinNode) bool {
+	bf := balanceFactor(x)
+	return -2 < bf && bf < 2
+}
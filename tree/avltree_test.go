@@ -0,0 +1,117 @@
+package tree
+
+import (
+	"github.com/joexzh/dsa"
+	"reflect"
+	"testing"
+)
+
+func TestAVLTree_PutRemove(t *testing.T) {
+	avl := NewAVLTree()
+	m := make(map[int]int)
+	for i, k := range randomKeys(1000, 700) {
+		_, exist := m[k]
+		if ok := avl.Put(dsa.Int64(k), i); ok == exist {
+			t.Fatalf("put key %d expected %v, got %v", k, !exist, ok)
+		}
+		m[k] = i
+		if err := avl.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for k, v := range m {
+		if got := avl.Get(dsa.Int64(k)); got != v {
+			t.Fatalf("key %d expected %v, got %v", k, v, got)
+		}
+	}
+
+	for _, k := range randomKeys(1000, 700) {
+		_, exist := m[k]
+		if ok := avl.Remove(dsa.Int64(k)); ok != exist {
+			t.Fatalf("remove key %d expected %v, got %v", k, exist, ok)
+		}
+		delete(m, k)
+		if err := avl.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if avl.Size() != len(m) {
+		t.Fatalf("expected size %d, got %d", len(m), avl.Size())
+	}
+}
+
+func TestAVLTree_Height(t *testing.T) {
+	avl := NewAVLTree()
+	for i := 0; i < 1023; i++ { // ascending keys make a plain BST a list
+		avl.Put(dsa.Int64(i), i)
+	}
+	if h := avl.Root().Height(); h > 13 { // 1.44 * log2(n)
+		t.Fatalf("expected height <= 13, got %d", h)
+	}
+}
+
+func TestAVLTree_JoinSplit(t *testing.T) {
+	for _, k := range []int{-1, 0, 1, 50, 137, 299, 300, 1000} {
+		avl := NewAVLTree()
+		keys := randomKeys(300, 300)
+		for _, key := range keys {
+			avl.Put(dsa.Int64(key), key)
+		}
+		expected := sortedUnique(keys)
+
+		right := avl.Split(dsa.Int64(k))
+		if err := avl.Validate(); err != nil {
+			t.Fatalf("split at %d, left: %v", k, err)
+		}
+		if err := right.Validate(); err != nil {
+			t.Fatalf("split at %d, right: %v", k, err)
+		}
+		i := 0
+		for i < len(expected) && expected[i] < k {
+			i++
+		}
+		if got := entryKeys(avl.Traverse); !reflect.DeepEqual(expected[:i], got) {
+			t.Fatalf("split at %d, left expected %v, got %v", k, expected[:i], got)
+		}
+		if got := entryKeys(right.Traverse); !reflect.DeepEqual(expected[i:], got) {
+			t.Fatalf("split at %d, right expected %v, got %v", k, expected[i:], got)
+		}
+
+		avl.Join(&right)
+		if err := avl.Validate(); err != nil {
+			t.Fatalf("join at %d: %v", k, err)
+		}
+		if !right.Empty() {
+			t.Fatalf("join at %d, expected other empty, got size %d", k, right.Size())
+		}
+		if got := entryKeys(avl.Traverse); !reflect.DeepEqual(expected, got) {
+			t.Fatalf("join at %d, expected %v, got %v", k, expected, got)
+		}
+	}
+}
+
+func TestAVLTree_JoinUnbalanced(t *testing.T) {
+	small, big := NewAVLTree(), NewAVLTree()
+	for i := 0; i < 3; i++ {
+		small.Put(dsa.Int64(i), i)
+	}
+	for i := 3; i < 1000; i++ {
+		big.Put(dsa.Int64(i), i)
+	}
+	small.Join(&big)
+	if err := small.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if small.Size() != 1000 {
+		t.Fatalf("expected size 1000, got %d", small.Size())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic joining smaller keys")
+		}
+	}()
+	other := NewAVLTree()
+	other.Put(dsa.Int64(0), 0)
+	small.Join(&other)
+}
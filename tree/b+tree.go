@@ -1 +1,473 @@
 package tree
+
+import (
+	"fmt"
+	"github.com/joexzh/dsa"
+	"github.com/joexzh/dsa/dict"
+	"github.com/joexzh/dsa/list"
+	"sort"
+)
+
+var _ dict.Dictionary = (*BPlusTree)(nil)
+
+// BPlusTree is a B+ tree of order m, all values live in leaves, which are linked for sequential scans.
+// Internal nodes have [ceil(m/2), m] children, leaves have [floor(m/2), m-1] entries, except root.
+// Keys are unique.
+type BPlusTree struct {
+	order int
+	size  int
+	root  *bpNode
+	head  *bpNode // the first leaf
+	tail  *bpNode // the last leaf
+}
+
+// bpNode is an internal node if child is not nil, where keys[i] separates child[i] < keys[i] <= child[i+1].
+// Otherwise it's a leaf, values[i] belongs to keys[i].
+type bpNode struct {
+	parent *bpNode
+	keys   []dsa.Item
+	child  []*bpNode
+	values []interface{}
+	prev   *bpNode // leaf only
+	next   *bpNode // leaf only
+}
+
+func (v *bpNode) isLeaf() bool {
+	return v.child == nil
+}
+
+// search returns the number of keys not greater than k, which is the rank of child to go down
+func (v *bpNode) search(k dsa.Item) int {
+	return sort.Search(len(v.keys), func(i int) bool {
+		return k.Less(v.keys[i])
+	})
+}
+
+func (v *bpNode) rank(c *bpNode) int {
+	for i, x := range v.child {
+		if x == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewBPlusTree creates a B+ tree of order m, m must be at least 3
+func NewBPlusTree(m int) BPlusTree {
+	if m < 3 {
+		panic("BPlusTree order must be at least 3")
+	}
+	leaf := &bpNode{}
+	return BPlusTree{order: m, root: leaf, head: leaf, tail: leaf}
+}
+
+func (t *BPlusTree) Order() int {
+	return t.order
+}
+
+func (t *BPlusTree) Size() int {
+	return t.size
+}
+
+func (t *BPlusTree) Empty() bool {
+	return t.size <= 0
+}
+
+// Get value of key, nil if not found
+func (t *BPlusTree) Get(k dsa.Item) interface{} {
+	v, r := t.search(k)
+	if r < 0 {
+		return nil
+	}
+	return v.values[r]
+}
+
+// Put inserts a key-value pair. If k already exists, replaces the value and returns false.
+func (t *BPlusTree) Put(k dsa.Item, value interface{}) bool {
+	v, r := t.search(k)
+	if r >= 0 {
+		v.values[r] = value
+		return false
+	}
+	r = v.search(k)
+	v.keys = insertItem(v.keys, r, k)
+	v.values = insertValue(v.values, r, value)
+	t.size++
+	t.solveOverflow(v)
+	return true
+}
+
+// Remove the entry of key k, returns false if not found
+func (t *BPlusTree) Remove(k dsa.Item) bool {
+	v, r := t.search(k)
+	if r < 0 {
+		return false
+	}
+	v.keys = removeItem(v.keys, r)
+	v.values = removeValue(v.values, r)
+	t.size--
+	t.solveUnderflow(v)
+	return true
+}
+
+// Traverse entries in ascending order, along the leaves
+func (t *BPlusTree) Traverse(f func(e dsa.Entry)) {
+	for v := t.head; v != nil; v = v.next {
+		for i := range v.keys {
+			f(dsa.Entry{K: v.keys[i], V: v.values[i]})
+		}
+	}
+}
+
+// GetRange by [startK, endK] of key range, returns ordered entries
+func (t *BPlusTree) GetRange(startK dsa.Item, endK dsa.Item) list.LinkedList {
+	ents := list.NewLinkedList()
+	for it := t.Scan(startK, endK); it.Next(); {
+		ents.InsertEnd(it.Entry())
+	}
+	return ents
+}
+
+// Scan returns an iterator of keys in [start, end] in ascending order,
+// it streams along the leaves without collecting entries.
+// A nil start or end means unbounded.
+func (t *BPlusTree) Scan(start dsa.Item, end dsa.Item) *BPlusIterator {
+	it := &BPlusIterator{v: t.head, start: start, end: end}
+	if start != nil {
+		v, _ := t.search(start)
+		i := sort.Search(len(v.keys), func(i int) bool { // the first one not less than start
+			return !v.keys[i].Less(start)
+		})
+		it.v, it.i = v, i
+	}
+	return it
+}
+
+// ReverseScan returns an iterator of keys in [start, end] in descending order, from end to start.
+// A nil start or end means unbounded.
+func (t *BPlusTree) ReverseScan(start dsa.Item, end dsa.Item) *BPlusIterator {
+	it := &BPlusIterator{v: t.tail, i: len(t.tail.keys) - 1, start: start, end: end, reverse: true}
+	if end != nil {
+		v, _ := t.search(end)
+		it.v, it.i = v, v.search(end)-1 // the last one not greater than end
+	}
+	return it
+}
+
+// Validate checks key order, separators, parent links, number of branches and entries,
+// all leaves are in the same depth and linked in order
+func (t *BPlusTree) Validate() error {
+	if t.root.parent != nil {
+		return fmt.Errorf("root has parent")
+	}
+	leafDepth := -1
+	var leaves []*bpNode
+	var check func(v *bpNode, depth int, lo dsa.Item, hi dsa.Item) error // keys of v are in [lo, hi)
+	check = func(v *bpNode, depth int, lo dsa.Item, hi dsa.Item) error {
+		for i, k := range v.keys {
+			if (i > 0 && !v.keys[i-1].Less(k)) || (lo != nil && k.Less(lo)) || (hi != nil && !k.Less(hi)) {
+				return fmt.Errorf("node %v key %v is out of order", v.keys, k)
+			}
+		}
+		if v.isLeaf() {
+			if len(v.values) != len(v.keys) {
+				return fmt.Errorf("leaf %v has %d values", v.keys, len(v.values))
+			}
+			if len(v.keys) > t.order-1 || (v != t.root && len(v.keys) < t.order/2) {
+				return fmt.Errorf("leaf %v has %d entries, order %d", v.keys, len(v.keys), t.order)
+			}
+			if leafDepth < 0 {
+				leafDepth = depth
+			} else if leafDepth != depth {
+				return fmt.Errorf("leaf %v in depth %d, expected %d", v.keys, depth, leafDepth)
+			}
+			leaves = append(leaves, v)
+			return nil
+		}
+		if len(v.child) != len(v.keys)+1 {
+			return fmt.Errorf("node %v has %d children", v.keys, len(v.child))
+		}
+		if len(v.child) > t.order || (v != t.root && len(v.child) < (t.order+1)/2) || len(v.child) < 2 {
+			return fmt.Errorf("node %v has %d branches, order %d", v.keys, len(v.child), t.order)
+		}
+		for i, c := range v.child {
+			if c.parent != v {
+				return fmt.Errorf("node %v has broken child link", v.keys)
+			}
+			clo, chi := lo, hi
+			if i > 0 {
+				clo = v.keys[i-1]
+			}
+			if i < len(v.keys) {
+				chi = v.keys[i]
+			}
+			if err := check(c, depth+1, clo, chi); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := check(t.root, 0, nil, nil); err != nil {
+		return err
+	}
+
+	if leaves[0] != t.head || leaves[len(leaves)-1] != t.tail || t.head.prev != nil || t.tail.next != nil {
+		return fmt.Errorf("broken head or tail of leaves")
+	}
+	n := 0
+	for i, v := range leaves {
+		if (i > 0 && v.prev != leaves[i-1]) || (i < len(leaves)-1 && v.next != leaves[i+1]) {
+			return fmt.Errorf("leaf %v has broken link", v.keys)
+		}
+		n += len(v.keys)
+	}
+	if n != t.size {
+		return fmt.Errorf("expected size %d, got %d", n, t.size)
+	}
+	return nil
+}
+
+// search returns the leaf where k should be, and the rank of k in it, -1 if not found
+func (t *BPlusTree) search(k dsa.Item) (*bpNode, int) {
+	v := t.root
+	for !v.isLeaf() {
+		v = v.child[v.search(k)]
+	}
+	r := v.search(k) - 1
+	if r >= 0 && !v.keys[r].Less(k) {
+		return v, r
+	}
+	return v, -1
+}
+
+// solveOverflow splits v if it's too big.
+// A leaf is split in halves, with a copy of the first key of the right half going up to the parent,
+// while an internal node moves its middle key up.
+func (t *BPlusTree) solveOverflow(v *bpNode) {
+	for {
+		var u *bpNode
+		var k dsa.Item
+		if v.isLeaf() {
+			if len(v.keys) < t.order {
+				return
+			}
+			s := len(v.keys) / 2
+			u = &bpNode{
+				keys:   append([]dsa.Item(nil), v.keys[s:]...),
+				values: append([]interface{}(nil), v.values[s:]...),
+				prev:   v,
+				next:   v.next,
+			}
+			v.keys = v.keys[:s:s]
+			v.values = v.values[:s:s]
+			if v.next != nil {
+				v.next.prev = u
+			} else {
+				t.tail = u
+			}
+			v.next = u
+			k = u.keys[0]
+		} else {
+			if len(v.child) <= t.order {
+				return
+			}
+			s := t.order / 2
+			u = &bpNode{
+				keys:  append([]dsa.Item(nil), v.keys[s+1:]...),
+				child: append([]*bpNode(nil), v.child[s+1:]...),
+			}
+			for _, c := range u.child {
+				c.parent = u
+			}
+			k = v.keys[s]
+			v.keys = v.keys[:s:s]
+			v.child = v.child[: s+1 : s+1]
+		}
+
+		p := v.parent
+		if p == nil { // split the root, the tree grows by one level
+			p = &bpNode{child: []*bpNode{v}}
+			v.parent = p
+			t.root = p
+		}
+		r := p.rank(v)
+		p.keys = insertItem(p.keys, r, k)
+		p.child = insertBPNode(p.child, r+1, u)
+		u.parent = p
+		v = p
+	}
+}
+
+// solveUnderflow fixes v if it's too small, by borrowing from a sibling or merging with a sibling
+func (t *BPlusTree) solveUnderflow(v *bpNode) {
+	for {
+		p := v.parent
+		if p == nil { // root
+			if !v.isLeaf() && len(v.child) == 1 { // the tree shrinks by one level
+				t.root = v.child[0]
+				t.root.parent = nil
+			}
+			return
+		}
+		if (v.isLeaf() && len(v.keys) >= t.order/2) || (!v.isLeaf() && len(v.child) >= (t.order+1)/2) {
+			return
+		}
+
+		r := p.rank(v)
+		var ls, rs *bpNode
+		if r > 0 {
+			ls = p.child[r-1]
+		}
+		if r < len(p.keys) {
+			rs = p.child[r+1]
+		}
+		if v.isLeaf() {
+			if ls != nil && len(ls.keys) > t.order/2 { // borrow the last entry of left sibling
+				last := len(ls.keys) - 1
+				v.keys = insertItem(v.keys, 0, ls.keys[last])
+				v.values = insertValue(v.values, 0, ls.values[last])
+				ls.keys, ls.values = removeItem(ls.keys, last), removeValue(ls.values, last)
+				p.keys[r-1] = v.keys[0]
+				return
+			}
+			if rs != nil && len(rs.keys) > t.order/2 { // borrow the first entry of right sibling
+				v.keys = append(v.keys, rs.keys[0])
+				v.values = append(v.values, rs.values[0])
+				rs.keys, rs.values = removeItem(rs.keys, 0), removeValue(rs.values, 0)
+				p.keys[r] = rs.keys[0]
+				return
+			}
+		} else {
+			if ls != nil && len(ls.child) > (t.order+1)/2 { // rotate right through the parent
+				last := len(ls.keys)
+				v.keys = insertItem(v.keys, 0, p.keys[r-1])
+				v.child = insertBPNode(v.child, 0, ls.child[last])
+				v.child[0].parent = v
+				p.keys[r-1] = ls.keys[last-1]
+				ls.keys, ls.child = removeItem(ls.keys, last-1), removeBPNode(ls.child, last)
+				return
+			}
+			if rs != nil && len(rs.child) > (t.order+1)/2 { // rotate left through the parent
+				v.keys = append(v.keys, p.keys[r])
+				v.child = append(v.child, rs.child[0])
+				rs.child[0].parent = v
+				p.keys[r] = rs.keys[0]
+				rs.keys, rs.child = removeItem(rs.keys, 0), removeBPNode(rs.child, 0)
+				return
+			}
+		}
+
+		if ls != nil { // merge v into left sibling
+			t.merge(ls, p.keys[r-1], v)
+			p.keys, p.child = removeItem(p.keys, r-1), removeBPNode(p.child, r)
+		} else { // merge right sibling into v
+			t.merge(v, p.keys[r], rs)
+			p.keys, p.child = removeItem(p.keys, r), removeBPNode(p.child, r+1)
+		}
+		v = p
+	}
+}
+
+// merge moves all of u into v, u is the right sibling of v and k is the separator between them
+func (t *BPlusTree) merge(v *bpNode, k dsa.Item, u *bpNode) {
+	if v.isLeaf() {
+		v.keys = append(v.keys, u.keys...)
+		v.values = append(v.values, u.values...)
+		v.next = u.next
+		if u.next != nil {
+			u.next.prev = v
+		} else {
+			t.tail = v
+		}
+		return
+	}
+	v.keys = append(append(v.keys, k), u.keys...)
+	v.child = append(v.child, u.child...)
+	for _, c := range u.child {
+		c.parent = v
+	}
+}
+
+// BPlusIterator walks entries along the leaves, the tree must not be modified during iteration.
+type BPlusIterator struct {
+	v       *bpNode
+	i       int
+	start   dsa.Item
+	end     dsa.Item
+	reverse bool
+	curr    dsa.Entry
+}
+
+// Next moves to the next entry, returns false if there is no more
+func (it *BPlusIterator) Next() bool {
+	if it.reverse {
+		for it.v != nil && it.i < 0 {
+			it.v = it.v.prev
+			if it.v != nil {
+				it.i = len(it.v.keys) - 1
+			}
+		}
+		if it.v == nil || (it.start != nil && it.v.keys[it.i].Less(it.start)) {
+			it.v = nil
+			return false
+		}
+		it.curr = dsa.Entry{K: it.v.keys[it.i], V: it.v.values[it.i]}
+		it.i--
+		return true
+	}
+
+	for it.v != nil && it.i >= len(it.v.keys) {
+		it.v, it.i = it.v.next, 0
+	}
+	if it.v == nil || (it.end != nil && it.end.Less(it.v.keys[it.i])) {
+		it.v = nil
+		return false
+	}
+	it.curr = dsa.Entry{K: it.v.keys[it.i], V: it.v.values[it.i]}
+	it.i++
+	return true
+}
+
+// Entry returns the current entry
+func (it *BPlusIterator) Entry() dsa.Entry {
+	return it.curr
+}
+
+func insertItem(s []dsa.Item, i int, k dsa.Item) []dsa.Item {
+	s = append(s, nil)
+	copy(s[i+1:], s[i:])
+	s[i] = k
+	return s
+}
+
+func removeItem(s []dsa.Item, i int) []dsa.Item {
+	copy(s[i:], s[i+1:])
+	s[len(s)-1] = nil
+	return s[:len(s)-1]
+}
+
+func insertValue(s []interface{}, i int, v interface{}) []interface{} {
+	s = append(s, nil)
+	copy(s[i+1:], s[i:])
+	s[i] = v
+	return s
+}
+
+func removeValue(s []interface{}, i int) []interface{} {
+	copy(s[i:], s[i+1:])
+	s[len(s)-1] = nil
+	return s[:len(s)-1]
+}
+
+func insertBPNode(s []*bpNode, i int, c *bpNode) []*bpNode {
+	s = append(s, nil)
+	copy(s[i+1:], s[i:])
+	s[i] = c
+	return s
+}
+
+func removeBPNode(s []*bpNode, i int) []*bpNode {
+	copy(s[i:], s[i+1:])
+	s[len(s)-1] = nil
+	return s[:len(s)-1]
+}
@@ -0,0 +1,91 @@
+package tree
+
+import (
+	"github.com/joexzh/dsa"
+	"reflect"
+	"testing"
+)
+
+func TestBPlusTree_PutRemove(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 16} {
+		bt := NewBPlusTree(order)
+		m := make(map[int]int)
+		for i, k := range randomKeys(1000, 700) {
+			_, exist := m[k]
+			if ok := bt.Put(dsa.Int64(k), i); ok == exist {
+				t.Fatalf("order %d put key %d expected %v, got %v", order, k, !exist, ok)
+			}
+			m[k] = i
+			if err := bt.Validate(); err != nil {
+				t.Fatalf("order %d: %v", order, err)
+			}
+		}
+		for k, v := range m {
+			if got := bt.Get(dsa.Int64(k)); got != v {
+				t.Fatalf("order %d key %d expected %v, got %v", order, k, v, got)
+			}
+		}
+
+		for _, k := range randomKeys(2000, 700) {
+			_, exist := m[k]
+			if ok := bt.Remove(dsa.Int64(k)); ok != exist {
+				t.Fatalf("order %d remove key %d expected %v, got %v", order, k, exist, ok)
+			}
+			delete(m, k)
+			if err := bt.Validate(); err != nil {
+				t.Fatalf("order %d: %v", order, err)
+			}
+		}
+		if bt.Size() != len(m) {
+			t.Fatalf("order %d expected size %d, got %d", order, len(m), bt.Size())
+		}
+	}
+}
+
+func TestBPlusTree_Scan(t *testing.T) {
+	bt := NewBPlusTree(4)
+	keys := randomKeys(500, 1000)
+	for _, k := range keys {
+		bt.Put(dsa.Int64(k), k)
+	}
+	expected := sortedUnique(keys)
+	if got := entryKeys(bt.Traverse); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("Traverse expected %v, got %v", expected, got)
+	}
+
+	item := func(k int) dsa.Item {
+		if k < 0 {
+			return nil // unbounded
+		}
+		return dsa.Int64(k)
+	}
+	for _, r := range [][2]int{{-1, -1}, {-1, 300}, {200, -1}, {200, 700}, {333, 333}, {700, 200}, {1000, 2000}} {
+		want := make([]int, 0)
+		for _, k := range expected {
+			if (r[0] < 0 || r[0] <= k) && (r[1] < 0 || k <= r[1]) {
+				want = append(want, k)
+			}
+		}
+
+		got := make([]int, 0)
+		for it := bt.Scan(item(r[0]), item(r[1])); it.Next(); {
+			got = append(got, int(it.Entry().K.(dsa.Int64)))
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("Scan %v expected %v, got %v", r, want, got)
+		}
+
+		got = got[:0]
+		for it := bt.ReverseScan(item(r[0]), item(r[1])); it.Next(); {
+			got = append([]int{int(it.Entry().K.(dsa.Int64))}, got...)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("ReverseScan %v expected %v, got %v", r, want, got)
+		}
+	}
+
+	empty := NewBPlusTree(3)
+	if empty.Scan(nil, nil).Next() || empty.ReverseScan(nil, nil).Next() {
+		t.Fatalf("expected no entry in empty tree")
+	}
+}
@@ -0,0 +1,175 @@
+package tree
+
+import "github.com/joexzh/dsa"
+
+type color uint8
+
+const (
+	red color = iota
+	black
+)
+
+// BinNode is the node shared by all binary trees in this package.
+// The height is the normal height in most trees, but black height in RBTree.
+type BinNode struct {
+	parent *BinNode
+	lc     *BinNode
+	rc     *BinNode
+	height int
+	size   int   // number of nodes of the subtree
+	color  color // only used by RBTree
+	entry  dsa.Entry
+	aug    interface{} // value of the subtree computed by Augment of the tree
+}
+
+func newBinNode(e dsa.Entry, parent *BinNode) *BinNode {
+	return &BinNode{entry: e, parent: parent, size: 1}
+}
+
+func (x *BinNode) Entry() dsa.Entry {
+	return x.entry
+}
+
+func (x *BinNode) Key() dsa.Item {
+	return x.entry.K
+}
+
+func (x *BinNode) Value() interface{} {
+	return x.entry.V
+}
+
+func (x *BinNode) Parent() *BinNode {
+	return x.parent
+}
+
+func (x *BinNode) Left() *BinNode {
+	return x.lc
+}
+
+func (x *BinNode) Right() *BinNode {
+	return x.rc
+}
+
+func (x *BinNode) Height() int {
+	return x.height
+}
+
+// Size of the subtree rooted at x
+func (x *BinNode) Size() int {
+	return sizeOf(x)
+}
+
+// Aug returns the augmented value of the subtree rooted at x, see BST.SetAugment
+func (x *BinNode) Aug() interface{} {
+	return x.aug
+}
+
+// Succ returns the in-order successor, nil if x is the last one
+func (x *BinNode) Succ() *BinNode {
+	s := x
+	if s.rc != nil { // the leftmost node of right subtree
+		s = s.rc
+		for s.lc != nil {
+			s = s.lc
+		}
+		return s
+	}
+	for s.isRChild() { // go up until s is a left child
+		s = s.parent
+	}
+	return s.parent
+}
+
+// Pred returns the in-order predecessor, nil if x is the first one
+func (x *BinNode) Pred() *BinNode {
+	s := x
+	if s.lc != nil { // the rightmost node of left subtree
+		s = s.lc
+		for s.rc != nil {
+			s = s.rc
+		}
+		return s
+	}
+	for s.isLChild() {
+		s = s.parent
+	}
+	return s.parent
+}
+
+func (x *BinNode) isRoot() bool {
+	return x.parent == nil
+}
+
+func (x *BinNode) isLChild() bool {
+	return x.parent != nil && x.parent.lc == x
+}
+
+func (x *BinNode) isRChild() bool {
+	return x.parent != nil && x.parent.rc == x
+}
+
+func (x *BinNode) sibling() *BinNode {
+	if x.isLChild() {
+		return x.parent.rc
+	}
+	return x.parent.lc
+}
+
+func (x *BinNode) uncle() *BinNode {
+	return x.parent.sibling()
+}
+
+// tallerChild returns the taller child of x, if equal, returns the one in the same side as x
+func (x *BinNode) tallerChild() *BinNode {
+	l, r := stature(x.lc), stature(x.rc)
+	if l > r {
+		return x.lc
+	}
+	if l < r {
+		return x.rc
+	}
+	if x.isLChild() {
+		return x.lc
+	}
+	return x.rc
+}
+
+// attach lc and rc as children of x
+func attach(x *BinNode, lc *BinNode, rc *BinNode) {
+	x.lc, x.rc = lc, rc
+	if lc != nil {
+		lc.parent = x
+	}
+	if rc != nil {
+		rc.parent = x
+	}
+}
+
+// stature is the height of a subtree, -1 for empty subtree
+func stature(x *BinNode) int {
+	if x == nil {
+		return -1
+	}
+	return x.height
+}
+
+// sizeOf is the size of a subtree, 0 for empty subtree
+func sizeOf(x *BinNode) int {
+	if x == nil {
+		return 0
+	}
+	return x.size
+}
+
+func isBlack(x *BinNode) bool {
+	return x == nil || x.color == black
+}
+
+func isRed(x *BinNode) bool {
+	return !isBlack(x)
+}
+
+// equal returns true if neither a nor b is less than the other
+func equal(a dsa.Item, b dsa.Item) bool {
+	return !a.Less(b) && !b.Less(a)
+}
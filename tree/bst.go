@@ -1 +1,440 @@
 package tree
+
+import (
+	"fmt"
+	"github.com/joexzh/dsa"
+	"github.com/joexzh/dsa/dict"
+	"github.com/joexzh/dsa/list"
+	"reflect"
+)
+
+var _ dict.Dictionary = (*BST)(nil)
+
+// BST is a binary search tree without balancing, keys are unique.
+// It is also the base of AVLTree, RBTree and SplayTree.
+type BST struct {
+	root *BinNode
+	hot  *BinNode // parent of the node hit by the last search, or where the missing key should be
+	size int
+
+	// updateHeight is the height rule of x, only looks at its children.
+	// Trees may have their own, such as the black height of RBTree.
+	updateHeight func(x *BinNode)
+	augment      Augment
+}
+
+// Augment computes the value of subtree x, from x itself and the values of its children, Aug of them.
+// It's called bottom-up whenever the subtree changes, including rotations, so must be o(1) to keep
+// the complexity of the tree. For example, the sum of values:
+//
+//	func(x *BinNode) interface{} {
+//		s := x.Value().(int)
+//		if x.Left() != nil {
+//			s += x.Left().Aug().(int)
+//		}
+//		if x.Right() != nil {
+//			s += x.Right().Aug().(int)
+//		}
+//		return s
+//	}
+type Augment func(x *BinNode) interface{}
+
+func NewBST() BST {
+	return BST{updateHeight: updateHeight}
+}
+
+func (t *BST) Size() int {
+	return t.size
+}
+
+func (t *BST) Empty() bool {
+	return t.size <= 0
+}
+
+func (t *BST) Root() *BinNode {
+	return t.root
+}
+
+// Search returns the node of key k, nil if not found
+func (t *BST) Search(k dsa.Item) *BinNode {
+	return *t.search(k)
+}
+
+// Get value of key, nil if not found
+func (t *BST) Get(k dsa.Item) interface{} {
+	if x := *t.search(k); x != nil {
+		return x.entry.V
+	}
+	return nil
+}
+
+// Put inserts a key-value pair. If k already exists, replaces the value and returns false.
+func (t *BST) Put(k dsa.Item, v interface{}) bool {
+	_, ok := t.insert(k, v)
+	return ok
+}
+
+// Remove the node of key k, returns false if not found
+func (t *BST) Remove(k dsa.Item) bool {
+	xp := t.search(k)
+	if *xp == nil {
+		return false
+	}
+	t.removeAt(xp)
+	t.size--
+	t.updateHeightAbove(t.hot)
+	return true
+}
+
+// SetAugment sets the augment function, and computes values of all nodes, o(n).
+// nil removes it.
+func (t *BST) SetAugment(f Augment) {
+	t.augment = f
+	t.root.TravPost(func(x *BinNode) {
+		x.aug = nil
+		if f != nil {
+			x.aug = f(x)
+		}
+	})
+}
+
+// Rank returns the number of keys less than k, o(logn)
+func (t *BST) Rank(k dsa.Item) int {
+	r := 0
+	for x := t.root; x != nil; {
+		if k.Less(x.entry.K) {
+			x = x.lc
+		} else if x.entry.K.Less(k) {
+			r += sizeOf(x.lc) + 1
+			x = x.rc
+		} else {
+			return r + sizeOf(x.lc)
+		}
+	}
+	return r
+}
+
+// Select returns the node of rank i, which is the (i+1)th smallest one, nil if i is out of [0, size), o(logn)
+func (t *BST) Select(i int) *BinNode {
+	if i < 0 || i >= t.size {
+		return nil
+	}
+	x := t.root
+	for {
+		l := sizeOf(x.lc)
+		if i < l {
+			x = x.lc
+		} else if i > l {
+			i -= l + 1
+			x = x.rc
+		} else {
+			return x
+		}
+	}
+}
+
+// CountRange returns the number of keys in [lo, hi], o(logn)
+func (t *BST) CountRange(lo dsa.Item, hi dsa.Item) int {
+	if hi.Less(lo) {
+		return 0
+	}
+	n := t.Rank(hi) - t.Rank(lo)
+	if t.contains(hi) {
+		n++
+	}
+	return n
+}
+
+// First returns the node of the smallest key, nil if empty
+func (t *BST) First() *BinNode {
+	x := t.root
+	for x != nil && x.lc != nil {
+		x = x.lc
+	}
+	return x
+}
+
+// Last returns the node of the biggest key, nil if empty
+func (t *BST) Last() *BinNode {
+	x := t.root
+	for x != nil && x.rc != nil {
+		x = x.rc
+	}
+	return x
+}
+
+// Floor returns the node of the biggest key not greater than k, nil if not exists
+func (t *BST) Floor(k dsa.Item) *BinNode {
+	var floor *BinNode
+	for x := t.root; x != nil; {
+		if k.Less(x.entry.K) {
+			x = x.lc
+		} else if x.entry.K.Less(k) {
+			floor = x
+			x = x.rc
+		} else {
+			return x
+		}
+	}
+	return floor
+}
+
+// Ceiling returns the node of the smallest key not less than k, nil if not exists
+func (t *BST) Ceiling(k dsa.Item) *BinNode {
+	var ceiling *BinNode
+	for x := t.root; x != nil; {
+		if k.Less(x.entry.K) {
+			ceiling = x
+			x = x.lc
+		} else if x.entry.K.Less(k) {
+			x = x.rc
+		} else {
+			return x
+		}
+	}
+	return ceiling
+}
+
+// GetRange by [startK, endK] of key range, returns ordered entries
+func (t *BST) GetRange(startK dsa.Item, endK dsa.Item) list.LinkedList {
+	ents := list.NewLinkedList()
+	for x := t.Ceiling(startK); x != nil && !endK.Less(x.entry.K); x = x.Succ() {
+		ents.InsertEnd(x.entry)
+	}
+	return ents
+}
+
+// Iter returns an iterator in ascending order from the smallest key
+func (t *BST) Iter() *Iterator {
+	return &Iterator{next: t.First()}
+}
+
+// IterFrom returns an iterator in ascending order from the smallest key not less than k
+func (t *BST) IterFrom(k dsa.Item) *Iterator {
+	return &Iterator{next: t.Ceiling(k)}
+}
+
+// ReverseIter returns an iterator in descending order from the biggest key
+func (t *BST) ReverseIter() *Iterator {
+	return &Iterator{next: t.Last(), reverse: true}
+}
+
+// ReverseIterFrom returns an iterator in descending order from the biggest key not greater than k
+func (t *BST) ReverseIterFrom(k dsa.Item) *Iterator {
+	return &Iterator{next: t.Floor(k), reverse: true}
+}
+
+// Traverse entries in ascending order
+func (t *BST) Traverse(f func(e dsa.Entry)) {
+	t.root.TravIn(func(x *BinNode) {
+		f(x.entry)
+	})
+}
+
+// search returns the reference of the node of key k, either t.root or the lc/rc field of its parent,
+// so the caller can replace it. If not found, *ref is nil and t.hot is where k should be attached.
+func (t *BST) search(k dsa.Item) **BinNode {
+	t.hot = nil
+	xp := &t.root
+	for *xp != nil {
+		x := *xp
+		if k.Less(x.entry.K) {
+			xp = &x.lc
+		} else if x.entry.K.Less(k) {
+			xp = &x.rc
+		} else {
+			break
+		}
+		t.hot = x
+	}
+	return xp
+}
+
+// insert k if not exists, otherwise replace the value. Returns the node of k and whether it is new.
+func (t *BST) insert(k dsa.Item, v interface{}) (*BinNode, bool) {
+	xp := t.search(k)
+	if *xp != nil {
+		(*xp).entry.V = v
+		if t.augment != nil { // the value may be a part of the augmented ones
+			t.augmentAbove(*xp)
+		}
+		return *xp, false
+	}
+	*xp = newBinNode(dsa.Entry{K: k, V: v}, t.hot)
+	t.size++
+	t.updateHeightAbove(*xp)
+	return *xp, true
+}
+
+// removeAt removes the node referenced by xp, which must be got from search.
+// If the node has two children, it swaps entry with its successor, and removes the successor instead.
+// Returns the node takes the place of the removed one, t.hot will be its parent.
+func (t *BST) removeAt(xp **BinNode) *BinNode {
+	x := *xp
+	w := x // the node actually removed
+	var succ *BinNode
+	if x.lc == nil {
+		succ = x.rc
+		*xp = succ
+	} else if x.rc == nil {
+		succ = x.lc
+		*xp = succ
+	} else {
+		w = x.Succ()
+		x.entry, w.entry = w.entry, x.entry
+		succ = w.rc
+		if u := w.parent; u == x {
+			u.rc = succ
+		} else {
+			u.lc = succ
+		}
+	}
+	t.hot = w.parent
+	if succ != nil {
+		succ.parent = t.hot
+	}
+	w.parent, w.lc, w.rc = nil, nil, nil
+	return succ
+}
+
+// fromParentTo returns the reference of x, either t.root or the lc/rc field of its parent
+func (t *BST) fromParentTo(x *BinNode) **BinNode {
+	if x.isRoot() {
+		return &t.root
+	}
+	if x.isLChild() {
+		return &x.parent.lc
+	}
+	return &x.parent.rc
+}
+
+// Validate checks the BST properties
+func (t *BST) Validate() error {
+	return t.validate()
+}
+
+// contains returns true if k exists, without touching t.hot
+func (t *BST) contains(k dsa.Item) bool {
+	for x := t.root; x != nil; {
+		if k.Less(x.entry.K) {
+			x = x.lc
+		} else if x.entry.K.Less(k) {
+			x = x.rc
+		} else {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *BST) updateHeightAbove(x *BinNode) {
+	for ; x != nil; x = x.parent {
+		t.update(x)
+	}
+}
+
+// update the height, size and augmented value of x by its children
+func (t *BST) update(x *BinNode) {
+	t.updateHeight(x)
+	t.augmentAt(x)
+}
+
+// augmentAt updates the size and augmented value of x by its children, without the height
+func (t *BST) augmentAt(x *BinNode) {
+	x.size = sizeOf(x.lc) + 1 + sizeOf(x.rc)
+	if t.augment != nil {
+		x.aug = t.augment(x)
+	}
+}
+
+func (t *BST) augmentAbove(x *BinNode) {
+	for ; x != nil; x = x.parent {
+		t.augmentAt(x)
+	}
+}
+
+// connect34 reconnects 3 nodes and 4 subtrees in the in-order of T0 a T1 b T2 c T3,
+// b becomes the root of the new subtree, the caller should attach b to the parent.
+func (t *BST) connect34(a, b, c, t0, t1, t2, t3 *BinNode) *BinNode {
+	attach(a, t0, t1)
+	t.update(a)
+	attach(c, t2, t3)
+	t.update(c)
+	attach(b, a, c)
+	t.update(b)
+	return b
+}
+
+// rotateAt does a single or double rotation around v, its parent p and grandparent g,
+// by 3+4 reconstruction. The new subtree root takes the place of g, and is returned.
+func (t *BST) rotateAt(v *BinNode) *BinNode {
+	p := v.parent
+	g := p.parent
+	gp := t.fromParentTo(g)
+	gParent := g.parent
+	var b *BinNode
+	if p.isLChild() {
+		if v.isLChild() { // zig-zig
+			b = t.connect34(v, p, g, v.lc, v.rc, p.rc, g.rc)
+		} else { // zag-zig
+			b = t.connect34(p, v, g, p.lc, v.lc, v.rc, g.rc)
+		}
+	} else {
+		if v.isRChild() { // zag-zag
+			b = t.connect34(g, p, v, g.lc, p.lc, v.lc, v.rc)
+		} else { // zig-zag
+			b = t.connect34(g, v, p, g.lc, v.lc, v.rc, p.rc)
+		}
+	}
+	b.parent = gParent
+	*gp = b
+	return b
+}
+
+// validate checks parent links, key order, size, heights by t.updateHeight, subtree sizes and augmented values
+func (t *BST) validate() error {
+	if t.root != nil && t.root.parent != nil {
+		return fmt.Errorf("root %v has parent %v", t.root.entry, t.root.parent.entry)
+	}
+	var err error
+	var last *BinNode
+	n := 0
+	t.root.TravIn(func(x *BinNode) {
+		if err != nil {
+			return
+		}
+		n++
+		if (x.lc != nil && x.lc.parent != x) || (x.rc != nil && x.rc.parent != x) {
+			err = fmt.Errorf("node %v has broken child link", x.entry)
+			return
+		}
+		if last != nil && !last.entry.K.Less(x.entry.K) {
+			err = fmt.Errorf("key %v is not less than %v", last.entry.K, x.entry.K)
+			return
+		}
+		last = x
+		y := *x
+		t.updateHeight(&y)
+		t.augmentAt(&y)
+		if y.height != x.height {
+			err = fmt.Errorf("node %v expected height %d, got %d", x.entry, y.height, x.height)
+		} else if y.size != x.size {
+			err = fmt.Errorf("node %v expected subtree size %d, got %d", x.entry, y.size, x.size)
+		} else if !reflect.DeepEqual(y.aug, x.aug) {
+			err = fmt.Errorf("node %v expected augmented value %v, got %v", x.entry, y.aug, x.aug)
+		}
+	})
+	if err == nil && n != t.size {
+		err = fmt.Errorf("expected size %d, got %d", n, t.size)
+	}
+	return err
+}
+
+func updateHeight(x *BinNode) {
+	l, r := stature(x.lc), stature(x.rc)
+	if l > r {
+		x.height = 1 + l
+	} else {
+		x.height = 1 + r
+	}
+}
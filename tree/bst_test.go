@@ -0,0 +1,301 @@
+package tree
+
+import (
+	"github.com/joexzh/dsa"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func randomKeys(n int, max int) []int {
+	keys := make([]int, n)
+	for i := range keys {
+		keys[i] = rand.Intn(max)
+	}
+	return keys
+}
+
+// sortedUnique returns the distinct keys in ascending order
+func sortedUnique(keys []int) []int {
+	m := make(map[int]bool, len(keys))
+	uniq := make([]int, 0, len(keys))
+	for _, k := range keys {
+		if !m[k] {
+			m[k] = true
+			uniq = append(uniq, k)
+		}
+	}
+	sort.Ints(uniq)
+	return uniq
+}
+
+func entryKeys(traverse func(f func(e dsa.Entry))) []int {
+	got := make([]int, 0)
+	traverse(func(e dsa.Entry) {
+		got = append(got, int(e.K.(dsa.Int64)))
+	})
+	return got
+}
+
+// checkBST checks parent links, key order and heights computed by f
+func checkBST(t *testing.T, root *BinNode, height func(x *BinNode) int) {
+	if root != nil && root.parent != nil {
+		t.Fatalf("root %v has parent %v", root.entry, root.parent.entry)
+	}
+	root.TravPost(func(x *BinNode) {
+		if x.lc != nil && (x.lc.parent != x || !x.lc.entry.K.Less(x.entry.K)) {
+			t.Fatalf("bad left child %v of %v", x.lc.entry, x.entry)
+		}
+		if x.rc != nil && (x.rc.parent != x || !x.entry.K.Less(x.rc.entry.K)) {
+			t.Fatalf("bad right child %v of %v", x.rc.entry, x.entry)
+		}
+		if h := height(x); h != x.height {
+			t.Fatalf("node %v expected height %d, got %d", x.entry, h, x.height)
+		}
+	})
+}
+
+func realHeight(x *BinNode) int {
+	if x == nil {
+		return -1
+	}
+	l, r := realHeight(x.lc), realHeight(x.rc)
+	if l > r {
+		return l + 1
+	}
+	return r + 1
+}
+
+func TestBST_PutGetRemove(t *testing.T) {
+	bst := NewBST()
+	keys := randomKeys(500, 300)
+	m := make(map[int]int)
+	for i, k := range keys {
+		_, exist := m[k]
+		if ok := bst.Put(dsa.Int64(k), i); ok == exist {
+			t.Fatalf("put key %d expected %v, got %v", k, !exist, ok)
+		}
+		m[k] = i
+	}
+	checkBST(t, bst.Root(), realHeight)
+	if bst.Size() != len(m) {
+		t.Fatalf("expected size %d, got %d", len(m), bst.Size())
+	}
+	for k, v := range m {
+		if got := bst.Get(dsa.Int64(k)); got != v {
+			t.Fatalf("key %d expected %v, got %v", k, v, got)
+		}
+	}
+	if got := bst.Get(dsa.Int64(-1)); got != nil {
+		t.Fatalf("expected nil, got %v", got)
+	}
+
+	for _, k := range randomKeys(500, 300) {
+		_, exist := m[k]
+		if ok := bst.Remove(dsa.Int64(k)); ok != exist {
+			t.Fatalf("remove key %d expected %v, got %v", k, exist, ok)
+		}
+		delete(m, k)
+		checkBST(t, bst.Root(), realHeight)
+	}
+	if bst.Size() != len(m) {
+		t.Fatalf("expected size %d, got %d", len(m), bst.Size())
+	}
+	for k := range m {
+		bst.Remove(dsa.Int64(k))
+	}
+	if !bst.Empty() || bst.Root() != nil {
+		t.Fatalf("expected empty, got size %d", bst.Size())
+	}
+}
+
+func TestBST_Traverse(t *testing.T) {
+	//        4
+	//      /   \
+	//     2     6
+	//    / \   /
+	//   1   3 5
+	bst := NewBST()
+	for _, k := range []int{4, 2, 6, 1, 3, 5} {
+		bst.Put(dsa.Int64(k), k)
+	}
+
+	trav := func(f func(x *BinNode, visit func(*BinNode))) []int {
+		got := make([]int, 0)
+		f(bst.Root(), func(x *BinNode) {
+			got = append(got, int(x.Key().(dsa.Int64)))
+		})
+		return got
+	}
+	cases := []struct {
+		name     string
+		f        func(x *BinNode, visit func(*BinNode))
+		expected []int
+	}{
+		{"TravPreR", (*BinNode).TravPreR, []int{4, 2, 1, 3, 6, 5}},
+		{"TravPre", (*BinNode).TravPre, []int{4, 2, 1, 3, 6, 5}},
+		{"TravInR", (*BinNode).TravInR, []int{1, 2, 3, 4, 5, 6}},
+		{"TravIn", (*BinNode).TravIn, []int{1, 2, 3, 4, 5, 6}},
+		{"TravPostR", (*BinNode).TravPostR, []int{1, 3, 2, 5, 6, 4}},
+		{"TravPost", (*BinNode).TravPost, []int{1, 3, 2, 5, 6, 4}},
+		{"TravLevel", (*BinNode).TravLevel, []int{4, 2, 6, 1, 3, 5}},
+	}
+	for _, c := range cases {
+		if got := trav(c.f); !reflect.DeepEqual(c.expected, got) {
+			t.Fatalf("%s expected %v, got %v", c.name, c.expected, got)
+		}
+	}
+}
+
+func TestBST_SuccPred(t *testing.T) {
+	bst := NewBST()
+	keys := randomKeys(200, 1000)
+	for _, k := range keys {
+		bst.Put(dsa.Int64(k), nil)
+	}
+	expected := sortedUnique(keys)
+
+	got := make([]int, 0, len(expected))
+	for x := bst.First(); x != nil; x = x.Succ() {
+		got = append(got, int(x.Key().(dsa.Int64)))
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("Succ expected %v, got %v", expected, got)
+	}
+
+	got = got[:0]
+	for x := bst.Last(); x != nil; x = x.Pred() {
+		got = append([]int{int(x.Key().(dsa.Int64))}, got...)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("Pred expected %v, got %v", expected, got)
+	}
+
+	if got := entryKeys(bst.Traverse); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("Traverse expected %v, got %v", expected, got)
+	}
+}
+
+func TestBST_RotateAt(t *testing.T) {
+	// every shape of v, p, g ends with the same balanced subtree 1 2 3
+	for _, order := range [][]int{{3, 2, 1}, {3, 1, 2}, {1, 2, 3}, {1, 3, 2}} {
+		bst := NewBST()
+		for _, k := range order {
+			bst.Put(dsa.Int64(k), k)
+		}
+		b := bst.rotateAt(bst.Search(dsa.Int64(order[2])))
+		if b != bst.Root() || b.Key() != dsa.Int64(2) || b.lc.Key() != dsa.Int64(1) || b.rc.Key() != dsa.Int64(3) {
+			t.Fatalf("order %v rotate failed", order)
+		}
+		checkBST(t, bst.Root(), realHeight)
+	}
+}
+
+func sumAugment(x *BinNode) interface{} {
+	s := x.Value().(int)
+	if x.Left() != nil {
+		s += x.Left().Aug().(int)
+	}
+	if x.Right() != nil {
+		s += x.Right().Aug().(int)
+	}
+	return s
+}
+
+type orderStatTree interface {
+	Put(k dsa.Item, v interface{}) bool
+	Remove(k dsa.Item) bool
+	Validate() error
+	Root() *BinNode
+	SetAugment(f Augment)
+	Rank(k dsa.Item) int
+	Select(i int) *BinNode
+	CountRange(lo dsa.Item, hi dsa.Item) int
+}
+
+func TestBST_OrderStatistic(t *testing.T) {
+	bst, avl, rb, splay := NewBST(), NewAVLTree(), NewRBTree(), NewSplayTree()
+	trees := map[string]orderStatTree{"BST": &bst, "AVLTree": &avl, "RBTree": &rb, "SplayTree": &splay}
+	for name, tr := range trees {
+		m := make(map[int]int)
+		for _, k := range randomKeys(300, 500) {
+			tr.Put(dsa.Int64(k), k)
+			m[k] = k
+		}
+		tr.SetAugment(sumAugment) // computes the existing ones
+		for i, k := range randomKeys(1500, 500) {
+			if i%3 == 0 {
+				tr.Remove(dsa.Int64(k))
+				delete(m, k)
+			} else {
+				tr.Put(dsa.Int64(k), i) // replacing the value changes the sum
+				m[k] = i
+			}
+		}
+		if err := tr.Validate(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		keys := make([]int, 0, len(m))
+		sum := 0
+		for k, v := range m {
+			keys = append(keys, k)
+			sum += v
+		}
+		sort.Ints(keys)
+		if got := tr.Root().Aug(); got != sum {
+			t.Fatalf("%s: expected sum %d, got %v", name, sum, got)
+		}
+		if got := tr.Root().Size(); got != len(keys) {
+			t.Fatalf("%s: expected root size %d, got %d", name, len(keys), got)
+		}
+		for i, k := range keys {
+			if x := tr.Select(i); x == nil || x.Key() != dsa.Int64(k) {
+				t.Fatalf("%s: select %d expected %d, got %v", name, i, k, x)
+			}
+		}
+		if tr.Select(-1) != nil || tr.Select(len(keys)) != nil {
+			t.Fatalf("%s: expected nil selecting out of range", name)
+		}
+		for k := -1; k <= 501; k++ {
+			r := sort.SearchInts(keys, k)
+			if got := tr.Rank(dsa.Int64(k)); got != r {
+				t.Fatalf("%s: rank %d expected %d, got %d", name, k, r, got)
+			}
+		}
+		for i := 0; i < 200; i++ {
+			lo, hi := rand.Intn(520)-10, rand.Intn(520)-10
+			n := sort.SearchInts(keys, hi+1) - sort.SearchInts(keys, lo)
+			if n < 0 {
+				n = 0
+			}
+			if got := tr.CountRange(dsa.Int64(lo), dsa.Int64(hi)); got != n {
+				t.Fatalf("%s: count [%d, %d] expected %d, got %d", name, lo, hi, n, got)
+			}
+		}
+	}
+
+	// join and split keep them
+	for _, k := range []int{0, 100, 250, 499, 600} {
+		right := avl.Split(dsa.Int64(k))
+		if err := avl.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if err := right.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		avl.Join(&right)
+		if err := avl.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		sr := splay.Split(dsa.Int64(k))
+		if err := sr.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		splay.Join(&sr)
+		if err := splay.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
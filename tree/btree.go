@@ -1 +1,453 @@
 package tree
+
+import (
+	"fmt"
+	"github.com/joexzh/dsa"
+	"github.com/joexzh/dsa/dict"
+	"github.com/joexzh/dsa/list"
+	"sort"
+)
+
+var _ dict.Dictionary = (*BTree)(nil)
+
+// BTree is a B-tree of order m, each node except root has [ceil(m/2), m] branches.
+// Keys are unique.
+type BTree struct {
+	order int
+	size  int
+	root  *btNode
+	hot   *btNode // the last node visited by search
+}
+
+// btNode keeps len(entries)+1 children, children of a leaf are all nil
+type btNode struct {
+	parent  *btNode
+	entries []dsa.Entry
+	child   []*btNode
+}
+
+func newBTNode() *btNode {
+	return &btNode{child: []*btNode{nil}}
+}
+
+func (v *btNode) isLeaf() bool {
+	return v.child[0] == nil
+}
+
+// search returns the biggest rank whose key is not greater than k, -1 if all keys are greater than k
+func (v *btNode) search(k dsa.Item) int {
+	return sort.Search(len(v.entries), func(i int) bool {
+		return k.Less(v.entries[i].K)
+	}) - 1
+}
+
+// rank returns the index of child c
+func (v *btNode) rank(c *btNode) int {
+	for i, x := range v.child {
+		if x == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewBTree creates a B-tree of order m, m must be at least 3
+func NewBTree(m int) BTree {
+	if m < 3 {
+		panic("BTree order must be at least 3")
+	}
+	return BTree{order: m, root: newBTNode()}
+}
+
+// NewBTreeFromSorted bulk loads entries in strictly ascending order of keys, o(n),
+// nodes are filled evenly. Panic if entries are not sorted.
+func NewBTreeFromSorted(m int, entries []dsa.Entry) BTree {
+	t := NewBTree(m)
+	for i := 1; i < len(entries); i++ {
+		if !entries[i-1].K.Less(entries[i].K) {
+			panic("NewBTreeFromSorted: entries must be in strictly ascending order")
+		}
+	}
+	if len(entries) == 0 {
+		return t
+	}
+	h := 0 // the lowest height can hold all entries
+	for capacity(m, h) < len(entries) {
+		h++
+	}
+	t.root = t.build(entries, h)
+	t.size = len(entries)
+	return t
+}
+
+func (t *BTree) Order() int {
+	return t.order
+}
+
+func (t *BTree) Size() int {
+	return t.size
+}
+
+func (t *BTree) Empty() bool {
+	return t.size <= 0
+}
+
+// Get value of key, nil if not found
+func (t *BTree) Get(k dsa.Item) interface{} {
+	v, r := t.search(k)
+	if v == nil {
+		return nil
+	}
+	return v.entries[r].V
+}
+
+// Put inserts a key-value pair. If k already exists, replaces the value and returns false.
+func (t *BTree) Put(k dsa.Item, v interface{}) bool {
+	x, r := t.search(k)
+	if x != nil {
+		x.entries[r].V = v
+		return false
+	}
+	x = t.hot // always a leaf
+	r = x.search(k) + 1
+	x.entries = insertEntry(x.entries, r, dsa.Entry{K: k, V: v})
+	x.child = insertChild(x.child, r+1, nil)
+	t.size++
+	t.solveOverflow(x)
+	return true
+}
+
+// Remove the entry of key k, returns false if not found
+func (t *BTree) Remove(k dsa.Item) bool {
+	v, r := t.search(k)
+	if v == nil {
+		return false
+	}
+	if !v.isLeaf() { // swap with the successor in leaf
+		u := v.child[r+1]
+		for !u.isLeaf() {
+			u = u.child[0]
+		}
+		v.entries[r] = u.entries[0]
+		v, r = u, 0
+	}
+	v.entries = removeEntry(v.entries, r)
+	v.child = removeChild(v.child, r+1)
+	t.size--
+	t.solveUnderflow(v)
+	return true
+}
+
+// Traverse entries in ascending order
+func (t *BTree) Traverse(f func(e dsa.Entry)) {
+	for it := t.Iter(); it.Next(); {
+		f(it.Entry())
+	}
+}
+
+// GetRange by [startK, endK] of key range, returns ordered entries
+func (t *BTree) GetRange(startK dsa.Item, endK dsa.Item) list.LinkedList {
+	ents := list.NewLinkedList()
+	for it := t.IterFrom(startK); it.Next() && !endK.Less(it.Entry().K); {
+		ents.InsertEnd(it.Entry())
+	}
+	return ents
+}
+
+// Iter returns an iterator in ascending order from the smallest key
+func (t *BTree) Iter() *BTreeIterator {
+	v := t.root
+	for !v.isLeaf() {
+		v = v.child[0]
+	}
+	return &BTreeIterator{v: v}
+}
+
+// IterFrom returns an iterator in ascending order from the smallest key not less than k
+func (t *BTree) IterFrom(k dsa.Item) *BTreeIterator {
+	it := &BTreeIterator{}
+	for v := t.root; v != nil; {
+		r := v.search(k)
+		if r >= 0 && !v.entries[r].K.Less(k) { // hit
+			it.v, it.i = v, r
+			return it
+		}
+		if r+1 < len(v.entries) { // the ceiling so far, smaller ones may be in child
+			it.v, it.i = v, r+1
+		}
+		v = v.child[r+1]
+	}
+	return it
+}
+
+// Validate checks key order, parent links, number of branches, and all leaves are in the same depth
+func (t *BTree) Validate() error {
+	if t.root.parent != nil {
+		return fmt.Errorf("root has parent")
+	}
+	n := 0
+	leafDepth := -1
+	var last dsa.Item
+	var check func(v *btNode, depth int) error
+	check = func(v *btNode, depth int) error {
+		if len(v.child) != len(v.entries)+1 {
+			return fmt.Errorf("node %v has %d children", v.entries, len(v.child))
+		}
+		if len(v.child) > t.order || (v != t.root && len(v.child) < (t.order+1)/2) {
+			return fmt.Errorf("node %v has %d branches, order %d", v.entries, len(v.child), t.order)
+		}
+		if v.isLeaf() {
+			if leafDepth < 0 {
+				leafDepth = depth
+			} else if leafDepth != depth {
+				return fmt.Errorf("leaf %v in depth %d, expected %d", v.entries, depth, leafDepth)
+			}
+		}
+		for i := 0; i <= len(v.entries); i++ {
+			if c := v.child[i]; c != nil {
+				if c.parent != v {
+					return fmt.Errorf("node %v has broken child link", v.entries)
+				}
+				if err := check(c, depth+1); err != nil {
+					return err
+				}
+			} else if !v.isLeaf() {
+				return fmt.Errorf("internal node %v has nil child", v.entries)
+			}
+			if i < len(v.entries) {
+				if last != nil && !last.Less(v.entries[i].K) {
+					return fmt.Errorf("key %v is not less than %v", last, v.entries[i].K)
+				}
+				last = v.entries[i].K
+				n++
+			}
+		}
+		return nil
+	}
+	if err := check(t.root, 0); err != nil {
+		return err
+	}
+	if n != t.size {
+		return fmt.Errorf("expected size %d, got %d", n, t.size)
+	}
+	return nil
+}
+
+// search returns the node and rank of key k, nil if not found, and t.hot is the leaf where k should be
+func (t *BTree) search(k dsa.Item) (*btNode, int) {
+	t.hot = nil
+	for v := t.root; v != nil; {
+		r := v.search(k)
+		if r >= 0 && !v.entries[r].K.Less(k) {
+			return v, r
+		}
+		t.hot = v
+		v = v.child[r+1]
+	}
+	return nil, -1
+}
+
+// solveOverflow splits v if it has more than m branches, the middle key goes up to the parent
+func (t *BTree) solveOverflow(v *btNode) {
+	for len(v.child) > t.order {
+		s := t.order / 2 // the middle rank
+		u := &btNode{
+			entries: append([]dsa.Entry(nil), v.entries[s+1:]...),
+			child:   append([]*btNode(nil), v.child[s+1:]...),
+		}
+		if !u.isLeaf() {
+			for _, c := range u.child {
+				c.parent = u
+			}
+		}
+		e := v.entries[s]
+		v.entries = v.entries[:s:s]
+		v.child = v.child[: s+1 : s+1]
+
+		p := v.parent
+		if p == nil { // split the root, the tree grows by one level
+			p = newBTNode()
+			p.child[0] = v
+			v.parent = p
+			t.root = p
+		}
+		r := p.search(e.K) + 1
+		p.entries = insertEntry(p.entries, r, e)
+		p.child = insertChild(p.child, r+1, u)
+		u.parent = p
+		v = p
+	}
+}
+
+// solveUnderflow fixes v with less than ceil(m/2) branches,
+// by borrowing a key from a sibling through the parent, or merging with a sibling
+func (t *BTree) solveUnderflow(v *btNode) {
+	for len(v.child) < (t.order+1)/2 {
+		p := v.parent
+		if p == nil { // root
+			if len(v.entries) == 0 && v.child[0] != nil { // the tree shrinks by one level
+				t.root = v.child[0]
+				t.root.parent = nil
+			}
+			return
+		}
+		r := p.rank(v)
+		if r > 0 { // borrow from left sibling
+			ls := p.child[r-1]
+			if len(ls.child) > (t.order+1)/2 {
+				last := len(ls.entries) - 1
+				v.entries = insertEntry(v.entries, 0, p.entries[r-1])
+				p.entries[r-1] = ls.entries[last]
+				v.child = insertChild(v.child, 0, ls.child[last+1])
+				if v.child[0] != nil {
+					v.child[0].parent = v
+				}
+				ls.entries = ls.entries[:last]
+				ls.child = ls.child[:last+1]
+				return
+			}
+		}
+		if r < len(p.entries) { // borrow from right sibling
+			rs := p.child[r+1]
+			if len(rs.child) > (t.order+1)/2 {
+				v.entries = append(v.entries, p.entries[r])
+				p.entries[r] = rs.entries[0]
+				v.child = append(v.child, rs.child[0])
+				if c := v.child[len(v.child)-1]; c != nil {
+					c.parent = v
+				}
+				rs.entries = removeEntry(rs.entries, 0)
+				rs.child = removeChild(rs.child, 0)
+				return
+			}
+		}
+		if r > 0 { // merge v into left sibling, with the key between them
+			ls := p.child[r-1]
+			t.merge(ls, p.entries[r-1], v)
+			p.entries = removeEntry(p.entries, r-1)
+			p.child = removeChild(p.child, r)
+		} else { // merge right sibling into v
+			rs := p.child[r+1]
+			t.merge(v, p.entries[r], rs)
+			p.entries = removeEntry(p.entries, r)
+			p.child = removeChild(p.child, r+1)
+		}
+		v = p
+	}
+}
+
+// merge moves e and all of u into v
+func (t *BTree) merge(v *btNode, e dsa.Entry, u *btNode) {
+	v.entries = append(append(v.entries, e), u.entries...)
+	v.child = append(v.child, u.child...)
+	if !u.isLeaf() {
+		for _, c := range u.child {
+			c.parent = v
+		}
+	}
+}
+
+// build a subtree of height h from sorted entries, children share entries evenly
+func (t *BTree) build(entries []dsa.Entry, h int) *btNode {
+	v := newBTNode()
+	if h == 0 {
+		v.entries = append(v.entries, entries...)
+		v.child = make([]*btNode, len(entries)+1)
+		return v
+	}
+	// the fewest children can hold all entries
+	c := (len(entries) + 1 + capacity(t.order, h-1)) / (capacity(t.order, h-1) + 1)
+	if c < 2 {
+		c = 2
+	}
+	n := len(entries) - (c - 1) // entries for children
+	lo := 0
+	for i := 0; i < c; i++ {
+		cn := n / c
+		if i < n%c {
+			cn++
+		}
+		child := t.build(entries[lo:lo+cn], h-1)
+		child.parent = v
+		if i == 0 {
+			v.child[0] = child
+		} else {
+			v.child = append(v.child, child)
+		}
+		lo += cn
+		if i < c-1 {
+			v.entries = append(v.entries, entries[lo])
+			lo++
+		}
+	}
+	return v
+}
+
+// BTreeIterator walks entries in order, the tree must not be modified during iteration.
+type BTreeIterator struct {
+	v    *btNode
+	i    int
+	curr dsa.Entry
+}
+
+// Next moves to the next entry, returns false if there is no more
+func (it *BTreeIterator) Next() bool {
+	if it.v == nil || it.i >= len(it.v.entries) {
+		return false
+	}
+	it.curr = it.v.entries[it.i]
+	if !it.v.isLeaf() { // the leftmost of right subtree
+		it.v = it.v.child[it.i+1]
+		for !it.v.isLeaf() {
+			it.v = it.v.child[0]
+		}
+		it.i = 0
+		return true
+	}
+	it.i++
+	for it.i >= len(it.v.entries) && it.v.parent != nil { // go up until there are keys on the right
+		it.i = it.v.parent.rank(it.v)
+		it.v = it.v.parent
+	}
+	return true
+}
+
+// Entry returns the current entry
+func (it *BTreeIterator) Entry() dsa.Entry {
+	return it.curr
+}
+
+// capacity is the max number of keys of a B-tree of order m and height h
+func capacity(m int, h int) int {
+	c := m - 1
+	for ; h > 0; h-- {
+		c = c*m + m - 1
+	}
+	return c
+}
+
+func insertEntry(s []dsa.Entry, i int, e dsa.Entry) []dsa.Entry {
+	s = append(s, dsa.Entry{})
+	copy(s[i+1:], s[i:])
+	s[i] = e
+	return s
+}
+
+func removeEntry(s []dsa.Entry, i int) []dsa.Entry {
+	copy(s[i:], s[i+1:])
+	s[len(s)-1] = dsa.Entry{}
+	return s[:len(s)-1]
+}
+
+func insertChild(s []*btNode, i int, c *btNode) []*btNode {
+	s = append(s, nil)
+	copy(s[i+1:], s[i:])
+	s[i] = c
+	return s
+}
+
+func removeChild(s []*btNode, i int) []*btNode {
+	copy(s[i:], s[i+1:])
+	s[len(s)-1] = nil
+	return s[:len(s)-1]
+}
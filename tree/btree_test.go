@@ -0,0 +1,108 @@
+package tree
+
+import (
+	"github.com/joexzh/dsa"
+	"github.com/joexzh/dsa/list"
+	"reflect"
+	"testing"
+)
+
+func TestBTree_PutRemove(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 16} {
+		bt := NewBTree(order)
+		m := make(map[int]int)
+		for i, k := range randomKeys(1000, 700) {
+			_, exist := m[k]
+			if ok := bt.Put(dsa.Int64(k), i); ok == exist {
+				t.Fatalf("order %d put key %d expected %v, got %v", order, k, !exist, ok)
+			}
+			m[k] = i
+			if err := bt.Validate(); err != nil {
+				t.Fatalf("order %d: %v", order, err)
+			}
+		}
+		for k, v := range m {
+			if got := bt.Get(dsa.Int64(k)); got != v {
+				t.Fatalf("order %d key %d expected %v, got %v", order, k, v, got)
+			}
+		}
+
+		for _, k := range randomKeys(2000, 700) {
+			_, exist := m[k]
+			if ok := bt.Remove(dsa.Int64(k)); ok != exist {
+				t.Fatalf("order %d remove key %d expected %v, got %v", order, k, exist, ok)
+			}
+			delete(m, k)
+			if err := bt.Validate(); err != nil {
+				t.Fatalf("order %d: %v", order, err)
+			}
+		}
+		if bt.Size() != len(m) {
+			t.Fatalf("order %d expected size %d, got %d", order, len(m), bt.Size())
+		}
+	}
+}
+
+func TestBTree_Range(t *testing.T) {
+	bt := NewBTree(5)
+	keys := randomKeys(500, 1000)
+	for _, k := range keys {
+		bt.Put(dsa.Int64(k), k)
+	}
+	expected := sortedUnique(keys)
+	if got := entryKeys(bt.Traverse); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("Traverse expected %v, got %v", expected, got)
+	}
+
+	for _, r := range [][2]int{{-10, 2000}, {200, 700}, {333, 333}, {700, 200}, {1000, 2000}} {
+		want := make([]int, 0)
+		for _, k := range expected {
+			if r[0] <= k && k <= r[1] {
+				want = append(want, k)
+			}
+		}
+		got := make([]int, 0)
+		rl := bt.GetRange(dsa.Int64(r[0]), dsa.Int64(r[1]))
+		rl.Traverse(func(nd *list.LinkedNode) {
+			got = append(got, int(nd.Data.(dsa.Entry).K.(dsa.Int64)))
+		})
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("GetRange %v expected %v, got %v", r, want, got)
+		}
+	}
+}
+
+func TestNewBTreeFromSorted(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 7, 16} {
+		for n := 0; n < 400; n++ {
+			entries := make([]dsa.Entry, n)
+			for i := range entries {
+				entries[i] = dsa.Entry{K: dsa.Int64(i * 2), V: i}
+			}
+			bt := NewBTreeFromSorted(order, entries)
+			if err := bt.Validate(); err != nil {
+				t.Fatalf("order %d size %d: %v", order, n, err)
+			}
+			got := make([]dsa.Entry, 0, n)
+			bt.Traverse(func(e dsa.Entry) {
+				got = append(got, e)
+			})
+			if !reflect.DeepEqual(entries, got) {
+				t.Fatalf("order %d size %d, expected %v, got %v", order, n, entries, got)
+			}
+			// still works after bulk loading
+			bt.Put(dsa.Int64(1), 1)
+			bt.Remove(dsa.Int64(0))
+			if err := bt.Validate(); err != nil {
+				t.Fatalf("order %d size %d: %v", order, n, err)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic on unsorted entries")
+		}
+	}()
+	NewBTreeFromSorted(3, []dsa.Entry{{K: dsa.Int64(2)}, {K: dsa.Int64(1)}})
+}
@@ -0,0 +1,128 @@
+package tree
+
+// FenwickTree (binary indexed tree) keeps prefix sums of a sequence of int64.
+// Node i (1-based) holds the sum of the lowbit(i) elements ending at i.
+// Add and PrefixSum are o(logn).
+type FenwickTree struct {
+	sum []int64 // sum[0] is unused
+}
+
+func NewFenwickTree(n int) FenwickTree {
+	return FenwickTree{sum: make([]int64, n+1)}
+}
+
+// NewFenwickTreeFrom builds from vals, o(n)
+func NewFenwickTreeFrom(vals []int64) FenwickTree {
+	t := NewFenwickTree(len(vals))
+	copy(t.sum[1:], vals)
+	for i := 1; i < len(t.sum); i++ { // add each node to its parent
+		if p := i + lowbit(i); p < len(t.sum) {
+			t.sum[p] += t.sum[i]
+		}
+	}
+	return t
+}
+
+func (t *FenwickTree) Len() int {
+	return len(t.sum) - 1
+}
+
+// Add delta to the element i
+func (t *FenwickTree) Add(i int, delta int64) {
+	if i < 0 || i >= t.Len() {
+		panic("FenwickTree: index out of bound")
+	}
+	for i++; i < len(t.sum); i += lowbit(i) {
+		t.sum[i] += delta
+	}
+}
+
+// PrefixSum returns the sum of elements [0, i)
+func (t *FenwickTree) PrefixSum(i int) int64 {
+	if i < 0 || i > t.Len() {
+		panic("FenwickTree: index out of bound")
+	}
+	var s int64
+	for ; i > 0; i -= lowbit(i) {
+		s += t.sum[i]
+	}
+	return s
+}
+
+// RangeSum returns the sum of elements [l, r)
+func (t *FenwickTree) RangeSum(l int, r int) int64 {
+	return t.PrefixSum(r) - t.PrefixSum(l)
+}
+
+// Get returns the element i
+func (t *FenwickTree) Get(i int) int64 {
+	return t.RangeSum(i, i+1)
+}
+
+// Search returns the smallest i that PrefixSum(i+1) >= s, or Len() if not found.
+// Elements must be non-negative, o(logn).
+func (t *FenwickTree) Search(s int64) int {
+	pos := 0
+	step := 1
+	for step*2 < len(t.sum) {
+		step *= 2
+	}
+	for ; step > 0; step /= 2 { // binary lifting, the sum of [0, pos) stays less than s
+		if next := pos + step; next < len(t.sum) && t.sum[next] < s {
+			pos = next
+			s -= t.sum[next]
+		}
+	}
+	return pos
+}
+
+// FenwickTree2D keeps prefix sums of a matrix of int64, Add and PrefixSum are o(logn*logm)
+type FenwickTree2D struct {
+	n, m int
+	sum  []int64 // (n+1)*(m+1), row 0 and column 0 are unused
+}
+
+func NewFenwickTree2D(n int, m int) FenwickTree2D {
+	return FenwickTree2D{n: n, m: m, sum: make([]int64, (n+1)*(m+1))}
+}
+
+// Dim returns the number of rows and columns
+func (t *FenwickTree2D) Dim() (int, int) {
+	return t.n, t.m
+}
+
+// Add delta to the element at row i, column j
+func (t *FenwickTree2D) Add(i int, j int, delta int64) {
+	if i < 0 || j < 0 || i >= t.n || j >= t.m {
+		panic("FenwickTree2D: index out of bound")
+	}
+	for x := i + 1; x <= t.n; x += lowbit(x) {
+		for y := j + 1; y <= t.m; y += lowbit(y) {
+			t.sum[x*(t.m+1)+y] += delta
+		}
+	}
+}
+
+// PrefixSum returns the sum of rows [0, i) and columns [0, j)
+func (t *FenwickTree2D) PrefixSum(i int, j int) int64 {
+	if i < 0 || j < 0 || i > t.n || j > t.m {
+		panic("FenwickTree2D: index out of bound")
+	}
+	var s int64
+	for x := i; x > 0; x -= lowbit(x) {
+		for y := j; y > 0; y -= lowbit(y) {
+			s += t.sum[x*(t.m+1)+y]
+		}
+	}
+	return s
+}
+
+// RangeSum returns the sum of rows [r1, r2) and columns [c1, c2)
+func (t *FenwickTree2D) RangeSum(r1 int, c1 int, r2 int, c2 int) int64 {
+	return t.PrefixSum(r2, c2) - t.PrefixSum(r1, c2) - t.PrefixSum(r2, c1) + t.PrefixSum(r1, c1)
+}
+
+// lowbit is the lowest set bit of i
+func lowbit(i int) int {
+	return i & -i
+}
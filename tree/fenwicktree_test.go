@@ -0,0 +1,79 @@
+package tree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestFenwickTree(t *testing.T) {
+	for _, n := range []int{0, 1, 5, 64, 100} {
+		vals := make([]int64, n)
+		for i := range vals {
+			vals[i] = int64(rand.Intn(10))
+		}
+		ft := NewFenwickTreeFrom(vals)
+		for op := 0; op < 500 && n > 0; op++ {
+			i := rand.Intn(n)
+			d := int64(rand.Intn(10))
+			ft.Add(i, d)
+			vals[i] += d
+
+			l, r := randomRange(n)
+			var s int64
+			for k := l; k < r; k++ {
+				s += vals[k]
+			}
+			if got := ft.RangeSum(l, r); got != s {
+				t.Fatalf("n %d sum [%d, %d) expected %d, got %d", n, l, r, s, got)
+			}
+			if got := ft.Get(i); got != vals[i] {
+				t.Fatalf("n %d get %d expected %d, got %d", n, i, vals[i], got)
+			}
+		}
+		var prefix int64
+		for i := 0; i <= n; i++ {
+			if got := ft.PrefixSum(i); got != prefix {
+				t.Fatalf("n %d prefix sum %d expected %d, got %d", n, i, prefix, got)
+			}
+			if i < n {
+				prefix += vals[i]
+			}
+		}
+		for s := int64(0); s <= prefix+1; s++ {
+			expected := 0
+			for expected < n && ft.PrefixSum(expected+1) < s {
+				expected++
+			}
+			if got := ft.Search(s); got != expected {
+				t.Fatalf("n %d search %d expected %d, got %d", n, s, expected, got)
+			}
+		}
+	}
+}
+
+func TestFenwickTree2D(t *testing.T) {
+	n, m := 13, 20
+	ft := NewFenwickTree2D(n, m)
+	vals := make([][]int64, n)
+	for i := range vals {
+		vals[i] = make([]int64, m)
+	}
+	for op := 0; op < 1000; op++ {
+		i, j := rand.Intn(n), rand.Intn(m)
+		d := int64(rand.Intn(21) - 10)
+		ft.Add(i, j, d)
+		vals[i][j] += d
+
+		r1, r2 := randomRange(n)
+		c1, c2 := randomRange(m)
+		var s int64
+		for x := r1; x < r2; x++ {
+			for y := c1; y < c2; y++ {
+				s += vals[x][y]
+			}
+		}
+		if got := ft.RangeSum(r1, c1, r2, c2); got != s {
+			t.Fatalf("sum [%d, %d)x[%d, %d) expected %d, got %d", r1, r2, c1, c2, s, got)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package tree
+
+import (
+	"github.com/joexzh/dsa"
+)
+
+// Interval is the closed range [Lo, Hi] of items.
+// It's also an Item, ordered by Lo, then by Hi.
+type Interval struct {
+	Lo dsa.Item
+	Hi dsa.Item
+}
+
+func (i Interval) Less(than dsa.Item) bool {
+	o := than.(Interval)
+	if i.Lo.Less(o.Lo) {
+		return true
+	}
+	if o.Lo.Less(i.Lo) {
+		return false
+	}
+	return i.Hi.Less(o.Hi)
+}
+
+// Overlaps returns true if i and [lo, hi] have common points
+func (i Interval) Overlaps(lo dsa.Item, hi dsa.Item) bool {
+	return !hi.Less(i.Lo) && !i.Hi.Less(lo)
+}
+
+// Contains returns true if p is in i
+func (i Interval) Contains(p dsa.Item) bool {
+	return i.Overlaps(p, p)
+}
+
+// IntervalTree stores intervals in a red-black tree ordered by Interval,
+// each node is augmented by the max Hi of its subtree, to skip subtrees can't overlap.
+// Intervals are unique, the same interval twice replaces the value.
+type IntervalTree struct {
+	t RBTree
+}
+
+func NewIntervalTree() IntervalTree {
+	t := IntervalTree{t: NewRBTree()}
+	t.t.SetAugment(maxHi)
+	return t
+}
+
+func (t *IntervalTree) Size() int {
+	return t.t.Size()
+}
+
+func (t *IntervalTree) Empty() bool {
+	return t.t.Empty()
+}
+
+// Get value of interval iv, nil if not found
+func (t *IntervalTree) Get(iv Interval) interface{} {
+	return t.t.Get(iv)
+}
+
+// Put inserts an interval with value. If iv already exists, replaces the value and returns false.
+// Panics if iv.Hi < iv.Lo.
+func (t *IntervalTree) Put(iv Interval, v interface{}) bool {
+	if iv.Hi.Less(iv.Lo) {
+		panic("IntervalTree.Put: Hi of interval is less than Lo")
+	}
+	return t.t.Put(iv, v)
+}
+
+// Remove interval iv, returns false if not found
+func (t *IntervalTree) Remove(iv Interval) bool {
+	return t.t.Remove(iv)
+}
+
+// Overlap returns entries of intervals overlapping [lo, hi], ordered by Interval.
+// The keys of entries are Interval. o(k + klogn) for k results.
+func (t *IntervalTree) Overlap(lo dsa.Item, hi dsa.Item) []dsa.Entry {
+	res := make([]dsa.Entry, 0)
+	var walk func(x *BinNode)
+	walk = func(x *BinNode) {
+		if x == nil || x.aug.(dsa.Item).Less(lo) { // all of the subtree end before lo
+			return
+		}
+		walk(x.lc)
+		iv := x.entry.K.(Interval)
+		if iv.Overlaps(lo, hi) {
+			res = append(res, x.entry)
+		}
+		if !hi.Less(iv.Lo) { // the right ones start after iv.Lo, may not beyond hi
+			walk(x.rc)
+		}
+	}
+	walk(t.t.root)
+	return res
+}
+
+// Stab returns entries of intervals containing point p, ordered by Interval
+func (t *IntervalTree) Stab(p dsa.Item) []dsa.Entry {
+	return t.Overlap(p, p)
+}
+
+// Traverse entries in ascending order of Interval
+func (t *IntervalTree) Traverse(f func(e dsa.Entry)) {
+	t.t.Traverse(f)
+}
+
+// Validate checks the red-black tree and the max Hi of subtrees
+func (t *IntervalTree) Validate() error {
+	return t.t.Validate()
+}
+
+// maxHi is the Augment of IntervalTree, the max Hi of the subtree
+func maxHi(x *BinNode) interface{} {
+	m := x.entry.K.(Interval).Hi
+	for _, c := range []*BinNode{x.lc, x.rc} {
+		if c != nil && m.Less(c.aug.(dsa.Item)) {
+			m = c.aug.(dsa.Item)
+		}
+	}
+	return m
+}
@@ -0,0 +1,78 @@
+package tree
+
+import (
+	"github.com/joexzh/dsa"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func randomInterval(max int) Interval {
+	lo := rand.Intn(max)
+	return Interval{Lo: dsa.Int64(lo), Hi: dsa.Int64(lo + rand.Intn(max/10))}
+}
+
+func TestIntervalTree_Overlap(t *testing.T) {
+	it := NewIntervalTree()
+	m := make(map[Interval]int)
+	for i := 0; i < 3000; i++ {
+		iv := randomInterval(1000)
+		if i%3 == 2 {
+			_, exist := m[iv]
+			if ok := it.Remove(iv); ok != exist {
+				t.Fatalf("remove %v expected %v, got %v", iv, exist, ok)
+			}
+			delete(m, iv)
+			continue
+		}
+		_, exist := m[iv]
+		if ok := it.Put(iv, i); ok == exist {
+			t.Fatalf("put %v expected %v, got %v", iv, !exist, ok)
+		}
+		m[iv] = i
+	}
+	if err := it.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if it.Size() != len(m) {
+		t.Fatalf("expected size %d, got %d", len(m), it.Size())
+	}
+
+	all := make([]Interval, 0, len(m))
+	for iv := range m {
+		all = append(all, iv)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Less(all[j])
+	})
+	for i := 0; i < 300; i++ {
+		q := randomInterval(1100)
+		if i%2 == 0 {
+			q.Hi = q.Lo // stabbing
+		}
+		expected := make([]dsa.Entry, 0)
+		for _, iv := range all {
+			if iv.Overlaps(q.Lo, q.Hi) {
+				expected = append(expected, dsa.Entry{K: iv, V: m[iv]})
+			}
+		}
+		got := it.Overlap(q.Lo, q.Hi)
+		if i%2 == 0 {
+			got = it.Stab(q.Lo)
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Fatalf("overlap %v expected %v, got %v", q, expected, got)
+		}
+	}
+}
+
+func TestIntervalTree_PutInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic")
+		}
+	}()
+	it := NewIntervalTree()
+	it.Put(Interval{Lo: dsa.Int64(2), Hi: dsa.Int64(1)}, nil)
+}
@@ -1 +1,370 @@
 package tree
+
+import (
+	"math"
+)
+
+// Point in k-dimensional space
+type Point []float64
+
+// KDEntry is a point with its value
+type KDEntry struct {
+	P Point
+	V interface{}
+}
+
+// Metric is the distance of two points.
+// For pruning, it must not be less than the distance along any single axis, |a[i] - b[i]|,
+// which is true for Euclidean, Manhattan and other Minkowski distances.
+type Metric func(a Point, b Point) float64
+
+func Euclidean(a Point, b Point) float64 {
+	var s float64
+	for i := range a {
+		d := a[i] - b[i]
+		s += d * d
+	}
+	return math.Sqrt(s)
+}
+
+func Manhattan(a Point, b Point) float64 {
+	var s float64
+	for i := range a {
+		s += math.Abs(a[i] - b[i])
+	}
+	return s
+}
+
+// KDTree partitions k-dimensional points, each level splits by one axis in turn.
+// Points are unique.
+type KDTree struct {
+	k    int
+	root *kdNode
+	size int
+}
+
+type kdNode struct {
+	entry KDEntry
+	axis  int
+	lc    *kdNode // points less than entry.P[axis]
+	rc    *kdNode // points not less than entry.P[axis]
+}
+
+func NewKDTree(k int) KDTree {
+	if k < 1 {
+		panic("KDTree dimension must be at least 1")
+	}
+	return KDTree{k: k}
+}
+
+// NewKDTreeFromEntries builds a balanced tree, by partitioning around the median of each level, o(nlogn)
+func NewKDTreeFromEntries(k int, entries []KDEntry) KDTree {
+	t := NewKDTree(k)
+	es := make([]KDEntry, 0, len(entries))
+	seen := make(map[string]bool, len(entries)) // drop the duplicated points, the last one wins
+	for i := len(entries) - 1; i >= 0; i-- {
+		t.checkDim(entries[i].P)
+		if s := pointKey(entries[i].P); !seen[s] {
+			seen[s] = true
+			es = append(es, entries[i])
+		}
+	}
+	t.root = t.build(es, 0)
+	t.size = len(es)
+	return t
+}
+
+func (t *KDTree) Dim() int {
+	return t.k
+}
+
+func (t *KDTree) Size() int {
+	return t.size
+}
+
+func (t *KDTree) Empty() bool {
+	return t.size <= 0
+}
+
+// Get value of point p, nil if not found
+func (t *KDTree) Get(p Point) interface{} {
+	t.checkDim(p)
+	if x := *t.search(p); x != nil {
+		return x.entry.V
+	}
+	return nil
+}
+
+// Put inserts a point with value. If p already exists, replaces the value and returns false.
+func (t *KDTree) Put(p Point, v interface{}) bool {
+	t.checkDim(p)
+	xp := t.search(p)
+	if *xp != nil {
+		(*xp).entry.V = v
+		return false
+	}
+	axis := 0
+	if t.root != nil {
+		axis = t.hotAxis(p)
+	}
+	*xp = &kdNode{entry: KDEntry{P: append(Point(nil), p...), V: v}, axis: axis}
+	t.size++
+	return true
+}
+
+// Remove point p, returns false if not found
+func (t *KDTree) Remove(p Point) bool {
+	t.checkDim(p)
+	xp := t.search(p)
+	if *xp == nil {
+		return false
+	}
+	t.removeAt(xp)
+	t.size--
+	return true
+}
+
+// Range returns entries in the box [lo, hi] of each axis
+func (t *KDTree) Range(lo Point, hi Point) []KDEntry {
+	t.checkDim(lo)
+	t.checkDim(hi)
+	res := make([]KDEntry, 0)
+	var walk func(x *kdNode)
+	walk = func(x *kdNode) {
+		if x == nil {
+			return
+		}
+		p := x.entry.P
+		in := true
+		for i := range p {
+			if p[i] < lo[i] || p[i] > hi[i] {
+				in = false
+				break
+			}
+		}
+		if in {
+			res = append(res, x.entry)
+		}
+		if lo[x.axis] < p[x.axis] {
+			walk(x.lc)
+		}
+		if hi[x.axis] >= p[x.axis] {
+			walk(x.rc)
+		}
+	}
+	walk(t.root)
+	return res
+}
+
+// Nearest returns at most n entries closest to q by metric, in ascending order of distance
+func (t *KDTree) Nearest(q Point, n int, metric Metric) []KDEntry {
+	t.checkDim(q)
+	if n <= 0 {
+		return nil
+	}
+	best := make([]kdCandidate, 0, n+1) // sorted by distance
+	var walk func(x *kdNode)
+	walk = func(x *kdNode) {
+		if x == nil {
+			return
+		}
+		if d := metric(q, x.entry.P); len(best) < n || d < best[len(best)-1].d {
+			i := len(best)
+			for i > 0 && best[i-1].d > d {
+				i--
+			}
+			best = append(best, kdCandidate{})
+			copy(best[i+1:], best[i:])
+			best[i] = kdCandidate{x.entry, d}
+			if len(best) > n {
+				best = best[:n]
+			}
+		}
+		diff := q[x.axis] - x.entry.P[x.axis]
+		near, far := x.lc, x.rc
+		if diff >= 0 {
+			near, far = far, near
+		}
+		walk(near)
+		if len(best) < n || math.Abs(diff) < best[len(best)-1].d { // the other side may be closer
+			walk(far)
+		}
+	}
+	walk(t.root)
+	res := make([]KDEntry, len(best))
+	for i := range best {
+		res[i] = best[i].entry
+	}
+	return res
+}
+
+// Traverse entries in pre-order
+func (t *KDTree) Traverse(f func(e KDEntry)) {
+	var walk func(x *kdNode)
+	walk = func(x *kdNode) {
+		if x != nil {
+			f(x.entry)
+			walk(x.lc)
+			walk(x.rc)
+		}
+	}
+	walk(t.root)
+}
+
+type kdCandidate struct {
+	entry KDEntry
+	d     float64
+}
+
+// search returns the reference of the node of point p, if not found, *ref is nil and is where p should be
+func (t *KDTree) search(p Point) **kdNode {
+	xp := &t.root
+	for *xp != nil {
+		x := *xp
+		if equalPoint(x.entry.P, p) {
+			break
+		}
+		if p[x.axis] < x.entry.P[x.axis] {
+			xp = &x.lc
+		} else {
+			xp = &x.rc
+		}
+	}
+	return xp
+}
+
+// hotAxis returns the axis of the new node of p, next to the axis of its parent
+func (t *KDTree) hotAxis(p Point) int {
+	var hot *kdNode
+	for x := t.root; x != nil; {
+		hot = x
+		if p[x.axis] < x.entry.P[x.axis] {
+			x = x.lc
+		} else {
+			x = x.rc
+		}
+	}
+	return (hot.axis + 1) % t.k
+}
+
+// removeAt replaces the node by the one with min value of its axis in right subtree,
+// or moves the left subtree to right and does it the same if there is no right subtree.
+func (t *KDTree) removeAt(xp **kdNode) {
+	x := *xp
+	if x.lc == nil && x.rc == nil {
+		*xp = nil
+		return
+	}
+	if x.rc == nil {
+		x.rc, x.lc = x.lc, nil
+	}
+	mp := minAt(&x.rc, x.axis)
+	x.entry = (*mp).entry
+	t.removeAt(mp)
+}
+
+// minAt returns the reference of the node with min value of axis in subtree
+func minAt(xp **kdNode, axis int) **kdNode {
+	x := *xp
+	if x == nil {
+		return nil
+	}
+	best := xp
+	if x.axis == axis { // only the left subtree may have smaller ones
+		if l := minAt(&x.lc, axis); l != nil {
+			best = l
+		}
+		return best
+	}
+	for _, cp := range []**kdNode{minAt(&x.lc, axis), minAt(&x.rc, axis)} {
+		if cp != nil && (*cp).entry.P[axis] < (*best).entry.P[axis] {
+			best = cp
+		}
+	}
+	return best
+}
+
+// build a balanced subtree, the median of axis becomes the root, smaller ones go left
+func (t *KDTree) build(es []KDEntry, axis int) *kdNode {
+	if len(es) == 0 {
+		return nil
+	}
+	mid := len(es) / 2
+	selectKD(es, mid, axis)
+	v := es[mid].P[axis]
+	j := 0 // equal ones must go right, move them to the end of left part, then swap the median before them
+	for i := 0; i < mid; i++ {
+		if es[i].P[axis] < v {
+			es[i], es[j] = es[j], es[i]
+			j++
+		}
+	}
+	es[j], es[mid] = es[mid], es[j]
+	mid = j
+	next := (axis + 1) % t.k
+	return &kdNode{
+		entry: es[mid],
+		axis:  axis,
+		lc:    t.build(es[:mid], next),
+		rc:    t.build(es[mid+1:], next),
+	}
+}
+
+func (t *KDTree) checkDim(p Point) {
+	if len(p) != t.k {
+		panic("KDTree: point dimension mismatch")
+	}
+}
+
+// selectKD partially sorts es by axis, so es[n] is the one would be in sorted order,
+// smaller or equal ones are before it, and bigger or equal ones after it
+func selectKD(es []KDEntry, n int, axis int) {
+	lo, hi := 0, len(es)-1
+	for lo < hi {
+		pivot := es[(lo+hi)/2].P[axis]
+		i, j := lo, hi
+		for i <= j {
+			for es[i].P[axis] < pivot {
+				i++
+			}
+			for es[j].P[axis] > pivot {
+				j--
+			}
+			if i <= j {
+				es[i], es[j] = es[j], es[i]
+				i++
+				j--
+			}
+		}
+		if n <= j {
+			hi = j
+		} else if n >= i {
+			lo = i
+		} else {
+			return
+		}
+	}
+}
+
+func equalPoint(a Point, b Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func pointKey(p Point) string {
+	b := make([]byte, 0, len(p)*8)
+	for _, f := range p {
+		u := math.Float64bits(f)
+		for i := 0; i < 8; i++ {
+			b = append(b, byte(u>>(8*i)))
+		}
+	}
+	return string(b)
+}
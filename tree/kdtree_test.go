@@ -0,0 +1,185 @@
+package tree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func randomPoints(n int, k int, max int) []KDEntry {
+	es := make([]KDEntry, n)
+	for i := range es {
+		p := make(Point, k)
+		for j := range p {
+			p[j] = float64(rand.Intn(max)) // small range makes equal coordinates
+		}
+		es[i] = KDEntry{P: p, V: i}
+	}
+	return es
+}
+
+// checkKD checks every node splits its subtrees by its axis
+func checkKD(t *testing.T, kd *KDTree) {
+	n := 0
+	var check func(x *kdNode, lo []float64, hi []float64)
+	check = func(x *kdNode, lo []float64, hi []float64) { // lo <= p[i] < hi
+		if x == nil {
+			return
+		}
+		n++
+		for i, v := range x.entry.P {
+			if v < lo[i] || v >= hi[i] {
+				t.Fatalf("point %v out of bound %v %v", x.entry.P, lo, hi)
+			}
+		}
+		a := x.axis
+		h := append([]float64(nil), hi...)
+		h[a] = x.entry.P[a]
+		check(x.lc, lo, h)
+		l := append([]float64(nil), lo...)
+		l[a] = x.entry.P[a]
+		check(x.rc, l, hi)
+	}
+	lo, hi := make([]float64, kd.Dim()), make([]float64, kd.Dim())
+	for i := range lo {
+		lo[i], hi[i] = -1e18, 1e18
+	}
+	check(kd.root, lo, hi)
+	if n != kd.Size() {
+		t.Fatalf("expected size %d, got %d", n, kd.Size())
+	}
+}
+
+func TestKDTree_PutRemove(t *testing.T) {
+	kd := NewKDTree(3)
+	m := make(map[[3]float64]int)
+	for _, e := range randomPoints(1000, 3, 8) {
+		k := [3]float64{e.P[0], e.P[1], e.P[2]}
+		_, exist := m[k]
+		if ok := kd.Put(e.P, e.V); ok == exist {
+			t.Fatalf("put %v expected %v, got %v", e.P, !exist, ok)
+		}
+		m[k] = e.V.(int)
+	}
+	checkKD(t, &kd)
+	for k, v := range m {
+		if got := kd.Get(Point(k[:])); got != v {
+			t.Fatalf("point %v expected %v, got %v", k, v, got)
+		}
+	}
+
+	for _, e := range randomPoints(1000, 3, 8) {
+		k := [3]float64{e.P[0], e.P[1], e.P[2]}
+		_, exist := m[k]
+		if ok := kd.Remove(e.P); ok != exist {
+			t.Fatalf("remove %v expected %v, got %v", e.P, exist, ok)
+		}
+		delete(m, k)
+		checkKD(t, &kd)
+	}
+	for k, v := range m {
+		if got := kd.Get(Point(k[:])); got != v {
+			t.Fatalf("point %v expected %v, got %v", k, v, got)
+		}
+	}
+}
+
+func TestKDTree_DimMismatch(t *testing.T) {
+	kd := NewKDTree(2)
+	kd.Put(Point{1, 2}, 0)
+	for name, f := range map[string]func(){
+		"GetShort":    func() { kd.Get(Point{1}) },
+		"GetLong":     func() { kd.Get(Point{1, 2, 3}) },
+		"RemoveShort": func() { kd.Remove(Point{1}) },
+		"RemoveLong":  func() { kd.Remove(Point{1, 2, 3}) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: expected panic of dimension mismatch", name)
+				}
+			}()
+			f()
+		}()
+	}
+	if kd.Size() != 1 || kd.Get(Point{1, 2}) != 0 {
+		t.Fatal("expected point {1, 2} kept")
+	}
+	if equalPoint(Point{1, 2}, Point{1, 2, 3}) {
+		t.Fatal("expected points of different dimensions not equal")
+	}
+}
+
+func TestKDTree_Build(t *testing.T) {
+	es := randomPoints(2000, 2, 1000)
+	kd := NewKDTreeFromEntries(2, es)
+	checkKD(t, &kd)
+	var depth func(x *kdNode) int
+	depth = func(x *kdNode) int {
+		if x == nil {
+			return 0
+		}
+		l, r := depth(x.lc), depth(x.rc)
+		if l > r {
+			return l + 1
+		}
+		return r + 1
+	}
+	if d := depth(kd.root); d > 15 { // log2(2000) = 11, a few more for equal coordinates
+		t.Fatalf("expected balanced tree, got depth %d", d)
+	}
+	for _, e := range es { // the last one of the duplicated points wins
+		if got := kd.Get(e.P); got.(int) < e.V.(int) {
+			t.Fatalf("point %v expected value >= %v, got %v", e.P, e.V, got)
+		}
+	}
+}
+
+func TestKDTree_Range(t *testing.T) {
+	es := randomPoints(1000, 2, 100)
+	kd := NewKDTreeFromEntries(2, es)
+	lo, hi := Point{20, 30}, Point{50, 45}
+	expected := make(map[int]bool)
+	for _, e := range es {
+		if kd.Get(e.P) == e.V && e.P[0] >= lo[0] && e.P[0] <= hi[0] && e.P[1] >= lo[1] && e.P[1] <= hi[1] {
+			expected[e.V.(int)] = true
+		}
+	}
+	got := kd.Range(lo, hi)
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d points, got %d", len(expected), len(got))
+	}
+	for _, e := range got {
+		if !expected[e.V.(int)] {
+			t.Fatalf("unexpected point %v", e.P)
+		}
+	}
+}
+
+func TestKDTree_Nearest(t *testing.T) {
+	es := randomPoints(1000, 3, 1000)
+	kd := NewKDTreeFromEntries(3, es)
+	all := make([]KDEntry, 0, kd.Size())
+	kd.Traverse(func(e KDEntry) {
+		all = append(all, e)
+	})
+	for _, metric := range []Metric{Euclidean, Manhattan} {
+		for _, q := range randomPoints(20, 3, 1000) {
+			got := kd.Nearest(q.P, 5, metric)
+			sort.Slice(all, func(i, j int) bool {
+				return metric(q.P, all[i].P) < metric(q.P, all[j].P)
+			})
+			if len(got) != 5 {
+				t.Fatalf("expected 5 points, got %d", len(got))
+			}
+			for i := range got { // compare distances, equal distances may be in any order
+				if metric(q.P, got[i].P) != metric(q.P, all[i].P) {
+					t.Fatalf("query %v the %dth expected %v, got %v", q.P, i, all[i].P, got[i].P)
+				}
+			}
+		}
+	}
+	if got := kd.Nearest(Point{0, 0, 0}, 2000, Euclidean); len(got) != kd.Size() {
+		t.Fatalf("expected all %d points, got %d", kd.Size(), len(got))
+	}
+}
@@ -0,0 +1,604 @@
+// Package paged is an on-disk B+ tree storage engine.
+//
+// The tree works on fixed-size pages through a Pager, caches them in a BufferPool with LRU eviction,
+// and recycles pages by a free list. Each Put or Remove is a transaction, pages it writes are logged
+// to a WAL before they can reach the data file, so the tree recovers to the last committed state on Open.
+package paged
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Meta page layout, little endian
+//
+//	magic uint32 | pageSize uint32 | root uint32 | freeHead uint32 | numPages uint32 | count uint64
+const (
+	metaMagic = 0x42505431 // "BPT1"
+	metaSize  = 28
+	metaPage  = PageID(0)
+	freePage  = 3 // page type of a page in the free list, followed by the next free page at offset 4
+)
+
+// Bounds of Options.PageSize. The smallest page is larger than 4 times an internal node without keys,
+// so the node underflows below a quarter of the page and is merged away, and it holds the meta
+// record of metaSize. The largest one keeps the uint16 number of cells from overflowing, even if all
+// keys and values are empty, see maxCell for their lengths.
+const (
+	minPageSize = 4 * (nodeHeaderSize + 4 + 1)
+	maxPageSize = nodeHeaderSize + 4*math.MaxUint16
+)
+
+var ErrTooLarge = errors.New("paged: key and value are too large for the page size")
+
+type Options struct {
+	PageSize        int // default 4096, in [minPageSize, maxPageSize]
+	PoolSize        int // number of cached pages, default 256
+	CheckpointPages int // checkpoint when the WAL holds this many pages, default 1024
+}
+
+func (o *Options) withDefaults() (Options, error) {
+	opts := Options{PageSize: 4096, PoolSize: 256, CheckpointPages: 1024}
+	if o != nil {
+		if o.PageSize > 0 {
+			opts.PageSize = o.PageSize
+		}
+		if o.PoolSize > 0 {
+			opts.PoolSize = o.PoolSize
+		}
+		if o.CheckpointPages > 0 {
+			opts.CheckpointPages = o.CheckpointPages
+		}
+	}
+	if opts.PageSize < minPageSize || opts.PageSize > maxPageSize {
+		return opts, fmt.Errorf("paged: page size %d out of [%d, %d]", opts.PageSize, minPageSize, maxPageSize)
+	}
+	return opts, nil
+}
+
+// BPlusTree maps []byte keys to []byte values in bytes.Compare order, all values live in leaves.
+// It's not safe for concurrent use.
+type BPlusTree struct {
+	pager Pager
+	pool  *BufferPool
+	wal   *WAL
+	meta  meta
+	opts  Options
+	buf   []byte // page buffer for encoding
+}
+
+type meta struct {
+	root     PageID
+	freeHead PageID // the first page in the free list, 0 if empty
+	numPages PageID
+	count    uint64
+}
+
+// Open the tree in file path, with its WAL in path+"-wal"
+func Open(path string, opts *Options) (*BPlusTree, error) {
+	o, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	pager, err := OpenFilePager(path, o.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	wal, err := OpenWAL(path+"-wal", o.PageSize)
+	if err != nil {
+		pager.Close()
+		return nil, err
+	}
+	t, err := New(pager, wal, &o)
+	if err != nil {
+		wal.Close()
+		pager.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// New recovers committed transactions from wal into pager, then loads the tree,
+// or creates an empty one if the pager is empty. The page size of opts must match the pager.
+func New(pager Pager, wal *WAL, opts *Options) (*BPlusTree, error) {
+	o, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	if pager.PageSize() != o.PageSize {
+		return nil, fmt.Errorf("paged: pager page size %d, expected %d", pager.PageSize(), o.PageSize)
+	}
+	if err := wal.Replay(pager.WritePage); err != nil {
+		return nil, err
+	}
+	if err := pager.Sync(); err != nil {
+		return nil, err
+	}
+	if err := wal.Reset(); err != nil {
+		return nil, err
+	}
+
+	t := &BPlusTree{pager: pager, pool: NewBufferPool(pager, o.PoolSize), wal: wal, opts: o, buf: make([]byte, o.PageSize)}
+	page, err := t.pool.Read(metaPage)
+	if err != nil {
+		return nil, err
+	}
+	switch binary.LittleEndian.Uint32(page) {
+	case metaMagic:
+		if ps := int(binary.LittleEndian.Uint32(page[4:])); ps != o.PageSize {
+			return nil, fmt.Errorf("paged: file page size %d, expected %d", ps, o.PageSize)
+		}
+		t.meta = meta{
+			root:     PageID(binary.LittleEndian.Uint32(page[8:])),
+			freeHead: PageID(binary.LittleEndian.Uint32(page[12:])),
+			numPages: PageID(binary.LittleEndian.Uint32(page[16:])),
+			count:    binary.LittleEndian.Uint64(page[20:]),
+		}
+	case 0: // new file, page 1 is an empty leaf as root
+		t.meta = meta{root: 1, numPages: 2}
+		err = t.update(func() error {
+			return t.writeNode(&node{id: 1, leaf: true})
+		})
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("paged: not a B+ tree file")
+	}
+	return t, nil
+}
+
+// Size returns the number of keys
+func (t *BPlusTree) Size() int {
+	return int(t.meta.count)
+}
+
+// Get returns the value of k, and whether it's found
+func (t *BPlusTree) Get(k []byte) ([]byte, bool, error) {
+	path, _, err := t.descend(k)
+	if err != nil {
+		return nil, false, err
+	}
+	leaf := path[len(path)-1]
+	if i := leaf.search(k) - 1; i >= 0 && bytes.Equal(leaf.keys[i], k) {
+		return leaf.vals[i], true, nil
+	}
+	return nil, false, nil
+}
+
+// Put inserts a key-value pair. If k already exists, replaces the value and returns false.
+func (t *BPlusTree) Put(k []byte, v []byte) (bool, error) {
+	if 4+len(k)+len(v) > t.maxCell() {
+		return false, ErrTooLarge
+	}
+	inserted := false
+	err := t.update(func() error {
+		path, idx, err := t.descend(k)
+		if err != nil {
+			return err
+		}
+		leaf := path[len(path)-1]
+		k, v := append([]byte(nil), k...), append([]byte(nil), v...)
+		if i := leaf.search(k); i > 0 && bytes.Equal(leaf.keys[i-1], k) {
+			leaf.vals[i-1] = v
+		} else {
+			leaf.insertCell(i, k, v, 0)
+			t.meta.count++
+			inserted = true
+		}
+		return t.fix(path, idx)
+	})
+	return inserted, err
+}
+
+// Remove k, returns false if not found
+func (t *BPlusTree) Remove(k []byte) (bool, error) {
+	path, idx, err := t.descend(k)
+	if err != nil {
+		return false, err
+	}
+	leaf := path[len(path)-1]
+	i := leaf.search(k) - 1
+	if i < 0 || !bytes.Equal(leaf.keys[i], k) {
+		return false, nil
+	}
+	err = t.update(func() error {
+		leaf.removeCell(i)
+		t.meta.count--
+		return t.fix(path, idx)
+	})
+	return err == nil, err
+}
+
+// Scan returns an iterator of keys in [start, end] in ascending order, it streams along the leaves.
+// A nil start or end means unbounded. The tree must not be modified during iteration.
+func (t *BPlusTree) Scan(start []byte, end []byte) *Iterator {
+	it := &Iterator{t: t, end: end}
+	if start == nil {
+		id := t.meta.root
+		for {
+			n, err := t.readNode(id)
+			if err != nil {
+				it.err = err
+				return it
+			}
+			if n.leaf {
+				it.n = n
+				return it
+			}
+			id = n.child[0]
+		}
+	}
+	path, _, err := t.descend(start)
+	if err != nil {
+		it.err = err
+		return it
+	}
+	it.n = path[len(path)-1]
+	it.i = sort.Search(len(it.n.keys), func(i int) bool { // the first one not less than start
+		return bytes.Compare(it.n.keys[i], start) >= 0
+	})
+	return it
+}
+
+// Checkpoint writes all committed pages to the data file, then empties the WAL
+func (t *BPlusTree) Checkpoint() error {
+	if err := t.pool.Flush(); err != nil {
+		return err
+	}
+	return t.wal.Reset()
+}
+
+// Close checkpoints and closes the files
+func (t *BPlusTree) Close() error {
+	err := t.Checkpoint()
+	if e := t.wal.Close(); err == nil {
+		err = e
+	}
+	if e := t.pager.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// Validate checks key order, separators, page sizes, all leaves are in the same depth and linked in order,
+// and every page is either in the tree or in the free list
+func (t *BPlusTree) Validate() error {
+	used := map[PageID]bool{metaPage: true}
+	var leaves []PageID
+	leafDepth := -1
+	var count uint64
+	var check func(id PageID, depth int, lo []byte, hi []byte) error // keys in [lo, hi)
+	check = func(id PageID, depth int, lo []byte, hi []byte) error {
+		if used[id] {
+			return fmt.Errorf("page %d is used twice", id)
+		}
+		used[id] = true
+		n, err := t.readNode(id)
+		if err != nil {
+			return err
+		}
+		if n.size() > t.opts.PageSize {
+			return fmt.Errorf("page %d overflows, size %d", id, n.size())
+		}
+		for i, k := range n.keys {
+			if (i > 0 && bytes.Compare(n.keys[i-1], k) >= 0) ||
+				(lo != nil && bytes.Compare(k, lo) < 0) || (hi != nil && bytes.Compare(k, hi) >= 0) {
+				return fmt.Errorf("page %d key %q is out of order", id, k)
+			}
+		}
+		if n.leaf {
+			if leafDepth < 0 {
+				leafDepth = depth
+			} else if depth != leafDepth {
+				return fmt.Errorf("leaf %d in depth %d, expected %d", id, depth, leafDepth)
+			}
+			leaves = append(leaves, id)
+			count += uint64(len(n.keys))
+			return nil
+		}
+		if id != t.meta.root && len(n.keys) == 0 {
+			return fmt.Errorf("internal page %d has no key", id)
+		}
+		for i, c := range n.child {
+			clo, chi := lo, hi
+			if i > 0 {
+				clo = n.keys[i-1]
+			}
+			if i < len(n.keys) {
+				chi = n.keys[i]
+			}
+			if err := check(c, depth+1, clo, chi); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := check(t.meta.root, 0, nil, nil); err != nil {
+		return err
+	}
+	if count != t.meta.count {
+		return fmt.Errorf("expected count %d, got %d", t.meta.count, count)
+	}
+
+	for i, id := range leaves {
+		n, err := t.readNode(id)
+		if err != nil {
+			return err
+		}
+		if (i < len(leaves)-1 && n.next != leaves[i+1]) || (i == len(leaves)-1 && n.next != 0) {
+			return fmt.Errorf("leaf %d has broken link", id)
+		}
+	}
+
+	for id := t.meta.freeHead; id != 0; {
+		if used[id] {
+			return fmt.Errorf("free page %d is used", id)
+		}
+		used[id] = true
+		page, err := t.pool.Read(id)
+		if err != nil {
+			return err
+		}
+		if page[0] != freePage {
+			return fmt.Errorf("page %d in free list is not free", id)
+		}
+		id = PageID(binary.LittleEndian.Uint32(page[4:]))
+	}
+	if len(used) != int(t.meta.numPages) {
+		return fmt.Errorf("%d pages are used or free, expected %d", len(used), t.meta.numPages)
+	}
+	return nil
+}
+
+// update runs f as a transaction, commits its pages with the meta page to the WAL,
+// or rolls back if anything fails
+func (t *BPlusTree) update(f func() error) error {
+	old := t.meta
+	err := f()
+	if err == nil {
+		err = t.writeMeta()
+	}
+	if err == nil {
+		err = t.pool.Commit(t.wal)
+	}
+	if err != nil {
+		t.pool.Rollback()
+		t.meta = old
+		return err
+	}
+	if t.wal.Pages() >= t.opts.CheckpointPages {
+		return t.Checkpoint()
+	}
+	return nil
+}
+
+// descend from root to the leaf where k should be,
+// returns nodes in the path, and idx[i] is the rank of path[i+1] in path[i]
+func (t *BPlusTree) descend(k []byte) (path []*node, idx []int, err error) {
+	id := t.meta.root
+	for {
+		n, err := t.readNode(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		path = append(path, n)
+		if n.leaf {
+			return path, idx, nil
+		}
+		i := n.search(k)
+		idx = append(idx, i)
+		id = n.child[i]
+	}
+}
+
+// fix writes the modified leaf at the end of path, and goes up to fix the overflowed or underflowed nodes.
+// An overflowed node splits into two, an underflowed one merges with a sibling,
+// or shares entries with it if they don't fit in one page.
+func (t *BPlusTree) fix(path []*node, idx []int) error {
+	for level := len(path) - 1; level >= 0; level-- {
+		n := path[level]
+		var p *node
+		if level > 0 {
+			p = path[level-1]
+		}
+
+		if n.size() > t.opts.PageSize {
+			u, sep := n.split()
+			id, err := t.alloc()
+			if err != nil {
+				return err
+			}
+			u.id = id
+			if n.leaf {
+				u.next, n.next = n.next, u.id
+			}
+			if err := t.writeNodes(n, u); err != nil {
+				return err
+			}
+			if p == nil { // split the root, the tree grows by one level
+				id, err := t.alloc()
+				if err != nil {
+					return err
+				}
+				t.meta.root = id
+				return t.writeNode(&node{id: id, keys: [][]byte{sep}, child: []PageID{n.id, u.id}})
+			}
+			p.insertCell(idx[level-1], sep, nil, u.id)
+			continue
+		}
+
+		if p == nil || n.size() >= t.opts.PageSize/4 || len(p.keys) == 0 {
+			if p == nil && !n.leaf && len(n.keys) == 0 { // root has only one child, the tree shrinks by one level
+				t.meta.root = n.child[0]
+				return t.free(n.id)
+			}
+			return t.writeNode(n)
+		}
+
+		// n underflows, take a sibling, l and r are adjacent children of p, separated by p.keys[si]
+		ci := idx[level-1]
+		si := ci
+		if ci > 0 {
+			si = ci - 1
+		}
+		l, err := t.readNode(p.child[si])
+		if err != nil {
+			return err
+		}
+		r, err := t.readNode(p.child[si+1])
+		if err != nil {
+			return err
+		}
+		if si == ci {
+			l = n
+		} else {
+			r = n
+		}
+		rid := r.id
+		l.merge(p.keys[si], r)
+		if l.size() <= t.opts.PageSize { // merge r into l
+			if err := t.writeNode(l); err != nil {
+				return err
+			}
+			if err := t.free(rid); err != nil {
+				return err
+			}
+			p.removeCell(si)
+			continue
+		}
+		// too big to merge, split them evenly again
+		r, sep := l.split()
+		r.id = rid
+		if l.leaf {
+			r.next, l.next = l.next, rid
+		}
+		if err := t.writeNodes(l, r); err != nil {
+			return err
+		}
+		p.keys[si] = sep
+	}
+	return nil
+}
+
+// maxCell is the largest leaf cell, so a node of 4 cells fits in a page, and the lengths fit in uint16
+func (t *BPlusTree) maxCell() int {
+	if c := (t.opts.PageSize - nodeHeaderSize - 4) / 4; c < math.MaxUint16 {
+		return c
+	}
+	return math.MaxUint16
+}
+
+func (t *BPlusTree) readNode(id PageID) (*node, error) {
+	page, err := t.pool.Read(id)
+	if err != nil {
+		return nil, err
+	}
+	return decodeNode(id, page)
+}
+
+func (t *BPlusTree) writeNode(n *node) error {
+	n.encode(t.buf)
+	return t.pool.Write(n.id, t.buf)
+}
+
+func (t *BPlusTree) writeNodes(nodes ...*node) error {
+	for _, n := range nodes {
+		if err := t.writeNode(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *BPlusTree) writeMeta() error {
+	for i := range t.buf {
+		t.buf[i] = 0
+	}
+	binary.LittleEndian.PutUint32(t.buf[0:], metaMagic)
+	binary.LittleEndian.PutUint32(t.buf[4:], uint32(t.opts.PageSize))
+	binary.LittleEndian.PutUint32(t.buf[8:], uint32(t.meta.root))
+	binary.LittleEndian.PutUint32(t.buf[12:], uint32(t.meta.freeHead))
+	binary.LittleEndian.PutUint32(t.buf[16:], uint32(t.meta.numPages))
+	binary.LittleEndian.PutUint64(t.buf[20:], t.meta.count)
+	return t.pool.Write(metaPage, t.buf)
+}
+
+// alloc takes a page from the free list, or a new page at the end of file
+func (t *BPlusTree) alloc() (PageID, error) {
+	if id := t.meta.freeHead; id != 0 {
+		page, err := t.pool.Read(id)
+		if err != nil {
+			return 0, err
+		}
+		t.meta.freeHead = PageID(binary.LittleEndian.Uint32(page[4:]))
+		return id, nil
+	}
+	id := t.meta.numPages
+	t.meta.numPages++
+	return id, nil
+}
+
+// free puts page id to the head of free list
+func (t *BPlusTree) free(id PageID) error {
+	for i := range t.buf {
+		t.buf[i] = 0
+	}
+	t.buf[0] = freePage
+	binary.LittleEndian.PutUint32(t.buf[4:], uint32(t.meta.freeHead))
+	t.meta.freeHead = id
+	return t.pool.Write(id, t.buf)
+}
+
+// Iterator walks entries along the leaves
+type Iterator struct {
+	t   *BPlusTree
+	n   *node
+	i   int
+	end []byte
+	key []byte
+	val []byte
+	err error
+}
+
+// Next moves to the next entry, returns false if there is no more or an error occurs
+func (it *Iterator) Next() bool {
+	if it.err != nil || it.n == nil {
+		return false
+	}
+	for it.i >= len(it.n.keys) {
+		if it.n.next == 0 {
+			it.n = nil
+			return false
+		}
+		if it.n, it.err = it.t.readNode(it.n.next); it.err != nil {
+			return false
+		}
+		it.i = 0
+	}
+	k := it.n.keys[it.i]
+	if it.end != nil && bytes.Compare(it.end, k) < 0 {
+		it.n = nil
+		return false
+	}
+	it.key, it.val = k, it.n.vals[it.i]
+	it.i++
+	return true
+}
+
+func (it *Iterator) Key() []byte {
+	return it.key
+}
+
+func (it *Iterator) Value() []byte {
+	return it.val
+}
+
+// Err returns the error stops the iteration, if any
+func (it *Iterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,308 @@
+package paged
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// small pages and pool make splits, merges and evictions happen a lot
+var testOpts = &Options{PageSize: 256, PoolSize: 8, CheckpointPages: 64}
+
+func openTest(t *testing.T, path string) *BPlusTree {
+	bt, err := Open(path, testOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bt
+}
+
+func key(i int) []byte {
+	return []byte(fmt.Sprintf("key%06d", i))
+}
+
+func value(i int) []byte {
+	return bytes.Repeat([]byte{byte(i)}, i%40)
+}
+
+func checkContent(t *testing.T, bt *BPlusTree, m map[int]bool) {
+	if err := bt.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if bt.Size() != len(m) {
+		t.Fatalf("expected size %d, got %d", len(m), bt.Size())
+	}
+	expected := make([]int, 0, len(m))
+	for i := range m {
+		expected = append(expected, i)
+	}
+	sort.Ints(expected)
+	got := make([]int, 0, len(m))
+	it := bt.Scan(nil, nil)
+	for it.Next() {
+		var i int
+		fmt.Sscanf(string(it.Key()), "key%06d", &i)
+		if !bytes.Equal(value(i), it.Value()) {
+			t.Fatalf("key %s expected value %v, got %v", it.Key(), value(i), it.Value())
+		}
+		got = append(got, i)
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected keys %v, got %v", expected, got)
+	}
+}
+
+func TestBPlusTree_PutRemove(t *testing.T) {
+	bt := openTest(t, filepath.Join(t.TempDir(), "db"))
+	defer bt.Close()
+
+	m := make(map[int]bool)
+	for _, i := range rand.Perm(1000) {
+		if ok, err := bt.Put(key(i), value(i)); err != nil || !ok {
+			t.Fatalf("put %d expected true, got %v %v", i, ok, err)
+		}
+		m[i] = true
+	}
+	if ok, err := bt.Put(key(7), value(7)); err != nil || ok {
+		t.Fatalf("put existing key expected false, got %v %v", ok, err)
+	}
+	checkContent(t, bt, m)
+
+	for i := 0; i < 1000; i++ {
+		v, ok, err := bt.Get(key(i))
+		if err != nil || !ok || !bytes.Equal(v, value(i)) {
+			t.Fatalf("get %d expected %v, got %v %v %v", i, value(i), v, ok, err)
+		}
+	}
+	if _, ok, _ := bt.Get([]byte("nope")); ok {
+		t.Fatalf("expected not found")
+	}
+
+	for _, i := range rand.Perm(1200) {
+		ok, err := bt.Remove(key(i))
+		if err != nil || ok != m[i] {
+			t.Fatalf("remove %d expected %v, got %v %v", i, m[i], ok, err)
+		}
+		delete(m, i)
+		if i%50 == 0 {
+			checkContent(t, bt, m)
+		}
+	}
+	checkContent(t, bt, m)
+
+	if _, err := bt.Put(key(1), make([]byte, 100)); err != ErrTooLarge {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestBPlusTree_PageSize(t *testing.T) {
+	dir := t.TempDir()
+	for _, ps := range []int{1, metaSize, 48, minPageSize - 1, maxPageSize + 1, 1 << 19} {
+		if bt, err := Open(filepath.Join(dir, fmt.Sprint("db", ps)), &Options{PageSize: ps}); err == nil {
+			bt.Close()
+			t.Fatalf("page size %d: expected error", ps)
+		}
+	}
+
+	for _, ps := range []int{minPageSize, maxPageSize} {
+		bt, err := Open(filepath.Join(dir, fmt.Sprint("db", ps)), &Options{PageSize: ps, PoolSize: 4})
+		if err != nil {
+			t.Fatalf("page size %d: %v", ps, err)
+		}
+		empty := []byte{}
+		if ok, err := bt.Put(empty, empty); err != nil || !ok {
+			t.Fatalf("page size %d: put empty key expected true, got %v %v", ps, ok, err)
+		}
+		k := bytes.Repeat([]byte{'k'}, bt.maxCell()-4) // the largest key
+		if ok, err := bt.Put(k, empty); err != nil || !ok {
+			t.Fatalf("page size %d: put key of %d expected true, got %v %v", ps, len(k), ok, err)
+		}
+		if v, ok, err := bt.Get(k); err != nil || !ok || len(v) != 0 {
+			t.Fatalf("page size %d: get key of %d expected found, got %v %v", ps, len(k), ok, err)
+		}
+		if _, err := bt.Put(append(k, 'k'), empty); err != ErrTooLarge {
+			t.Fatalf("page size %d: expected ErrTooLarge, got %v", ps, err)
+		}
+		for i := 0; i < 500; i++ { // splits and merges of the smallest nodes
+			bt.Put([]byte(fmt.Sprint(i)), empty)
+		}
+		for i := 0; i < 500; i += 2 {
+			bt.Remove([]byte(fmt.Sprint(i)))
+		}
+		if err := bt.Validate(); err != nil {
+			t.Fatalf("page size %d: %v", ps, err)
+		}
+		bt.Close()
+	}
+}
+
+func TestBPlusTree_Scan(t *testing.T) {
+	bt := openTest(t, filepath.Join(t.TempDir(), "db"))
+	defer bt.Close()
+	for i := 0; i < 500; i += 2 {
+		bt.Put(key(i), value(i))
+	}
+	cases := []struct {
+		start, end []byte
+		from, to   int // expected even keys in [from, to]
+	}{
+		{nil, nil, 0, 498},
+		{key(101), key(201), 102, 200},
+		{key(100), key(200), 100, 200},
+		{nil, key(7), 0, 6},
+		{key(491), nil, 492, 498},
+		{key(300), key(300), 300, 300},
+		{key(301), key(301), 1, 0},
+		{key(600), nil, 1, 0},
+	}
+	for _, c := range cases {
+		expected := make([]string, 0)
+		for i := c.from; i <= c.to; i += 2 {
+			expected = append(expected, string(key(i)))
+		}
+		got := make([]string, 0)
+		for it := bt.Scan(c.start, c.end); it.Next(); {
+			got = append(got, string(it.Key()))
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Fatalf("scan [%s, %s] expected %v, got %v", c.start, c.end, expected, got)
+		}
+	}
+}
+
+func TestBPlusTree_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	bt := openTest(t, path)
+	m := make(map[int]bool)
+	for i := 0; i < 300; i++ {
+		bt.Put(key(i), value(i))
+		m[i] = true
+	}
+	for i := 0; i < 300; i += 3 {
+		bt.Remove(key(i))
+		delete(m, i)
+	}
+	if err := bt.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(path + "-wal"); err != nil || fi.Size() != 0 {
+		t.Fatalf("expected empty WAL after close, got %v %v", fi.Size(), err)
+	}
+
+	bt = openTest(t, path)
+	defer bt.Close()
+	checkContent(t, bt, m)
+}
+
+func TestBPlusTree_Recover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	bt := openTest(t, path)
+	m := make(map[int]bool)
+	for i := 0; i < 300; i++ {
+		bt.Put(key(i), value(i))
+		m[i] = true
+	}
+	for i := 0; i < 300; i += 4 {
+		bt.Remove(key(i))
+		delete(m, i)
+	}
+	// crash, committed pages only reached the WAL or partly the data file
+	bt.wal.Close()
+	bt.pager.Close()
+
+	// and a torn transaction at the tail of WAL
+	f, err := os.OpenFile(path+"-wal", os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0x31, 0x4c, 0x41, 0x57, 3, 0, 0, 0, 1, 2, 3})
+	f.Close()
+
+	bt = openTest(t, path)
+	defer bt.Close()
+	checkContent(t, bt, m)
+}
+
+func TestWAL_CorruptHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db-wal")
+	wal, err := OpenWAL(path, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wal.Close()
+	if err := wal.Append(map[PageID][]byte{1: bytes.Repeat([]byte{1}, 64)}); err != nil {
+		t.Fatal(err)
+	}
+	// a header of 4G pages, far past the end of the file
+	if _, err := wal.f.WriteAt([]byte{0x31, 0x4c, 0x41, 0x57, 0xff, 0xff, 0xff, 0xff}, wal.off); err != nil {
+		t.Fatal(err)
+	}
+	var ids []PageID
+	if err := wal.Replay(func(id PageID, page []byte) error {
+		ids = append(ids, id)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []PageID{1}) || wal.Pages() != 1 {
+		t.Fatalf("expected page 1 replayed only, got %v", ids)
+	}
+}
+
+func TestBufferPool_LRU(t *testing.T) {
+	dir := t.TempDir()
+	pager, err := OpenFilePager(filepath.Join(dir, "db"), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pager.Close()
+	wal, err := OpenWAL(filepath.Join(dir, "db-wal"), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wal.Close()
+
+	bp := NewBufferPool(pager, 3)
+	page := func(b byte) []byte {
+		return bytes.Repeat([]byte{b}, 64)
+	}
+	for i := 1; i <= 5; i++ { // uncommitted pages are never evicted
+		bp.Write(PageID(i), page(byte(i)))
+	}
+	if bp.Size() != 5 {
+		t.Fatalf("expected 5 pages in transaction, got %d", bp.Size())
+	}
+	if err := bp.Commit(wal); err != nil {
+		t.Fatal(err)
+	}
+	bp.Read(1) // 1 is the most recently used, then 5, 4, 3, 2
+	bp.Read(6) // evicts 2, 3, 4 back to the capacity
+	bp.Read(7) // evicts 5
+	if bp.Size() != 3 {
+		t.Fatalf("expected 3 pages, got %d", bp.Size())
+	}
+	for _, id := range []PageID{2, 3, 4, 5} {
+		if _, ok := bp.frames[id]; ok {
+			t.Fatalf("expected page %d evicted", id)
+		}
+		buf := make([]byte, 64)
+		pager.ReadPage(id, buf)
+		if !bytes.Equal(buf, page(byte(id))) {
+			t.Fatalf("expected evicted page %d written back, got %v", id, buf)
+		}
+	}
+
+	bp.Write(1, page(9))
+	bp.Rollback()
+	if got, _ := bp.Read(1); !bytes.Equal(got, page(1)) {
+		t.Fatalf("expected page 1 rolled back, got %v", got)
+	}
+}
@@ -0,0 +1,178 @@
+package paged
+
+import (
+	"github.com/joexzh/dsa/list"
+)
+
+// BufferPool caches pages in memory, evicts the least recently used one when full.
+//
+// Pages written in the current transaction are never evicted until Commit logs them to the WAL,
+// so the data file only holds committed pages (no-steal). A committed dirty page is written back
+// to the pager when evicted or flushed.
+type BufferPool struct {
+	pager    Pager
+	capacity int
+	frames   map[PageID]*list.LinkedNode // Data is *frame
+	lru      list.LinkedList             // most recently used at first
+	txPages  map[PageID]bool             // pages written in the current transaction
+	before   map[PageID]frame            // images of the cached txPages before the transaction
+}
+
+type frame struct {
+	id    PageID
+	data  []byte
+	dirty bool
+}
+
+func NewBufferPool(pager Pager, capacity int) *BufferPool {
+	return &BufferPool{
+		pager:    pager,
+		capacity: capacity,
+		frames:   make(map[PageID]*list.LinkedNode),
+		lru:      list.NewLinkedList(),
+		txPages:  make(map[PageID]bool),
+		before:   make(map[PageID]frame),
+	}
+}
+
+// Read returns page id, the returned slice is only valid until the next call of the pool,
+// and must not be modified.
+func (bp *BufferPool) Read(id PageID) ([]byte, error) {
+	if nd, ok := bp.frames[id]; ok {
+		return bp.touch(nd).data, nil
+	}
+	fr, err := bp.newFrame(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := bp.pager.ReadPage(id, fr.data); err != nil {
+		bp.drop(id)
+		return nil, err
+	}
+	return fr.data, nil
+}
+
+// Write copies data to page id, as a part of the current transaction
+func (bp *BufferPool) Write(id PageID, data []byte) error {
+	var fr *frame
+	if nd, ok := bp.frames[id]; ok {
+		fr = bp.touch(nd)
+		if !bp.txPages[id] {
+			bp.before[id] = frame{id: id, data: append([]byte(nil), fr.data...), dirty: fr.dirty}
+		}
+	} else {
+		var err error
+		if fr, err = bp.newFrame(id); err != nil {
+			return err
+		}
+	}
+	copy(fr.data, data)
+	fr.dirty = true
+	bp.txPages[id] = true
+	return nil
+}
+
+// Commit logs pages written in the current transaction to wal, then they can be evicted
+func (bp *BufferPool) Commit(wal *WAL) error {
+	if len(bp.txPages) == 0 {
+		return nil
+	}
+	pages := make(map[PageID][]byte, len(bp.txPages))
+	for id := range bp.txPages {
+		pages[id] = bp.frames[id].Data.(*frame).data
+	}
+	if err := wal.Append(pages); err != nil {
+		return err
+	}
+	bp.txPages = make(map[PageID]bool)
+	bp.before = make(map[PageID]frame)
+	return nil
+}
+
+// Rollback restores pages written in the current transaction
+func (bp *BufferPool) Rollback() {
+	for id := range bp.txPages {
+		nd := bp.frames[id]
+		if fr, ok := bp.before[id]; ok {
+			*nd.Data.(*frame) = fr
+		} else {
+			bp.drop(id)
+		}
+	}
+	bp.txPages = make(map[PageID]bool)
+	bp.before = make(map[PageID]frame)
+}
+
+// Flush writes all committed dirty pages back to the pager, and syncs it
+func (bp *BufferPool) Flush() error {
+	for nd := bp.lru.First(); nd.Valid(); nd = nd.Succ() {
+		fr := nd.Data.(*frame)
+		if fr.dirty && !bp.txPages[fr.id] {
+			if err := bp.pager.WritePage(fr.id, fr.data); err != nil {
+				return err
+			}
+			fr.dirty = false
+		}
+	}
+	return bp.pager.Sync()
+}
+
+// Size returns the number of cached pages
+func (bp *BufferPool) Size() int {
+	return len(bp.frames)
+}
+
+// touch moves the frame to the most recently used position
+func (bp *BufferPool) touch(nd *list.LinkedNode) *frame {
+	fr := nd.Data.(*frame)
+	if nd != bp.lru.First() {
+		bp.lru.Remove(nd)
+		bp.lru.InsertStart(fr)
+		bp.frames[fr.id] = bp.lru.First()
+	}
+	return fr
+}
+
+// newFrame caches an empty frame of page id, evicts pages first until there is room, so the pool
+// shrinks back to its capacity after a transaction larger than it
+func (bp *BufferPool) newFrame(id PageID) (*frame, error) {
+	for len(bp.frames) >= bp.capacity {
+		ok, err := bp.evict()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+	}
+	fr := &frame{id: id, data: make([]byte, bp.pager.PageSize())}
+	bp.lru.InsertStart(fr)
+	bp.frames[id] = bp.lru.First()
+	return fr, nil
+}
+
+// evict the least recently used page not in the current transaction, returns false if none.
+// If all pages are in the transaction, the pool grows beyond its capacity.
+func (bp *BufferPool) evict() (bool, error) {
+	for nd := bp.lru.Last(); nd.Valid(); nd = nd.Pred() {
+		fr := nd.Data.(*frame)
+		if bp.txPages[fr.id] {
+			continue
+		}
+		if fr.dirty {
+			if err := bp.pager.WritePage(fr.id, fr.data); err != nil {
+				return false, err
+			}
+		}
+		bp.drop(fr.id)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (bp *BufferPool) drop(id PageID) {
+	if nd, ok := bp.frames[id]; ok {
+		bp.lru.Remove(nd)
+		delete(bp.frames, id)
+	}
+}
@@ -0,0 +1,195 @@
+package paged
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// Page layout of a node, little endian
+//
+//	header: type uint8 | unused uint8 | n uint16 | next uint32
+//	leaf cells: n * (klen uint16 | vlen uint16 | key | value)
+//	internal cells: child0 uint32 | n * (klen uint16 | key | child uint32)
+const (
+	nodeHeaderSize = 8
+	leafPage       = 1
+	internalPage   = 2
+)
+
+// node is a decoded B+ tree page.
+// Internal node has len(keys)+1 children, where keys[i] separates child[i] < keys[i] <= child[i+1].
+type node struct {
+	id    PageID
+	leaf  bool
+	keys  [][]byte
+	vals  [][]byte // leaf only
+	child []PageID // internal only
+	next  PageID   // leaf only, the next leaf, 0 if it's the last one
+}
+
+func decodeNode(id PageID, page []byte) (*node, error) {
+	n := &node{id: id}
+	switch page[0] {
+	case leafPage:
+		n.leaf = true
+	case internalPage:
+	default:
+		return nil, fmt.Errorf("page %d is not a node, type %d", id, page[0])
+	}
+	cnt := int(binary.LittleEndian.Uint16(page[2:]))
+	n.next = PageID(binary.LittleEndian.Uint32(page[4:]))
+	n.keys = make([][]byte, cnt)
+	off := nodeHeaderSize
+	if n.leaf {
+		n.vals = make([][]byte, cnt)
+		for i := 0; i < cnt; i++ {
+			klen := int(binary.LittleEndian.Uint16(page[off:]))
+			vlen := int(binary.LittleEndian.Uint16(page[off+2:]))
+			off += 4
+			n.keys[i] = append([]byte(nil), page[off:off+klen]...)
+			off += klen
+			n.vals[i] = append([]byte(nil), page[off:off+vlen]...)
+			off += vlen
+		}
+		return n, nil
+	}
+	n.child = make([]PageID, cnt+1)
+	n.child[0] = PageID(binary.LittleEndian.Uint32(page[off:]))
+	off += 4
+	for i := 0; i < cnt; i++ {
+		klen := int(binary.LittleEndian.Uint16(page[off:]))
+		off += 2
+		n.keys[i] = append([]byte(nil), page[off:off+klen]...)
+		off += klen
+		n.child[i+1] = PageID(binary.LittleEndian.Uint32(page[off:]))
+		off += 4
+	}
+	return n, nil
+}
+
+// encode n to page, n must fit in it
+func (n *node) encode(page []byte) {
+	for i := range page {
+		page[i] = 0
+	}
+	page[0] = internalPage
+	if n.leaf {
+		page[0] = leafPage
+	}
+	binary.LittleEndian.PutUint16(page[2:], uint16(len(n.keys)))
+	binary.LittleEndian.PutUint32(page[4:], uint32(n.next))
+	off := nodeHeaderSize
+	if n.leaf {
+		for i, k := range n.keys {
+			binary.LittleEndian.PutUint16(page[off:], uint16(len(k)))
+			binary.LittleEndian.PutUint16(page[off+2:], uint16(len(n.vals[i])))
+			off += 4
+			off += copy(page[off:], k)
+			off += copy(page[off:], n.vals[i])
+		}
+		return
+	}
+	binary.LittleEndian.PutUint32(page[off:], uint32(n.child[0]))
+	off += 4
+	for i, k := range n.keys {
+		binary.LittleEndian.PutUint16(page[off:], uint16(len(k)))
+		off += 2
+		off += copy(page[off:], k)
+		binary.LittleEndian.PutUint32(page[off:], uint32(n.child[i+1]))
+		off += 4
+	}
+}
+
+// size is the encoded size of n
+func (n *node) size() int {
+	s := nodeHeaderSize
+	if !n.leaf {
+		s += 4
+	}
+	for i := range n.keys {
+		s += n.cellSize(i)
+	}
+	return s
+}
+
+func (n *node) cellSize(i int) int {
+	if n.leaf {
+		return 4 + len(n.keys[i]) + len(n.vals[i])
+	}
+	return 2 + len(n.keys[i]) + 4
+}
+
+// search returns the number of keys not greater than k, which is the rank of child to go down
+func (n *node) search(k []byte) int {
+	return sort.Search(len(n.keys), func(i int) bool {
+		return bytes.Compare(k, n.keys[i]) < 0
+	})
+}
+
+// split n in halves by size, n keeps the left half, returns the right half and the separator.
+// The right half of a leaf keeps a copy of the separator, while an internal node moves it up.
+func (n *node) split() (*node, []byte) {
+	limit := len(n.keys) - 1 // the right half of an internal node keeps at least one key
+	if !n.leaf {
+		limit--
+	}
+	half, acc, mid := n.size()/2, nodeHeaderSize, 0
+	for mid < limit && acc+n.cellSize(mid) <= half {
+		acc += n.cellSize(mid)
+		mid++
+	}
+	if mid == 0 {
+		mid = 1
+	}
+	u := &node{leaf: n.leaf}
+	if n.leaf {
+		u.keys = append([][]byte(nil), n.keys[mid:]...)
+		u.vals = append([][]byte(nil), n.vals[mid:]...)
+		n.keys, n.vals = n.keys[:mid:mid], n.vals[:mid:mid]
+		return u, u.keys[0]
+	}
+	sep := n.keys[mid]
+	u.keys = append([][]byte(nil), n.keys[mid+1:]...)
+	u.child = append([]PageID(nil), n.child[mid+1:]...)
+	n.keys, n.child = n.keys[:mid:mid], n.child[:mid+1:mid+1]
+	return u, sep
+}
+
+// merge appends u to n, sep is the separator between them in parent
+func (n *node) merge(sep []byte, u *node) {
+	if n.leaf {
+		n.keys = append(n.keys, u.keys...)
+		n.vals = append(n.vals, u.vals...)
+		n.next = u.next
+		return
+	}
+	n.keys = append(append(n.keys, sep), u.keys...)
+	n.child = append(n.child, u.child...)
+}
+
+func (n *node) insertCell(i int, k []byte, v []byte, c PageID) {
+	n.keys = append(n.keys, nil)
+	copy(n.keys[i+1:], n.keys[i:])
+	n.keys[i] = k
+	if n.leaf {
+		n.vals = append(n.vals, nil)
+		copy(n.vals[i+1:], n.vals[i:])
+		n.vals[i] = v
+		return
+	}
+	n.child = append(n.child, 0)
+	copy(n.child[i+2:], n.child[i+1:])
+	n.child[i+1] = c
+}
+
+// removeCell removes keys[i], and vals[i] of leaf or child[i+1] of internal node
+func (n *node) removeCell(i int) {
+	n.keys = append(n.keys[:i], n.keys[i+1:]...)
+	if n.leaf {
+		n.vals = append(n.vals[:i], n.vals[i+1:]...)
+		return
+	}
+	n.child = append(n.child[:i+1], n.child[i+2:]...)
+}
@@ -0,0 +1,69 @@
+package paged
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// PageID is the index of a fixed-size page in the file, page 0 is the meta page
+type PageID uint32
+
+// Pager reads and writes fixed-size pages
+type Pager interface {
+	PageSize() int
+	// ReadPage fills buf with page id, a page never written reads as zeros
+	ReadPage(id PageID, buf []byte) error
+	WritePage(id PageID, buf []byte) error
+	Sync() error
+	Close() error
+}
+
+// FilePager stores page i at offset i*pageSize of a file
+type FilePager struct {
+	f        *os.File
+	pageSize int
+}
+
+func OpenFilePager(path string, pageSize int) (*FilePager, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FilePager{f: f, pageSize: pageSize}, nil
+}
+
+func (p *FilePager) PageSize() int {
+	return p.pageSize
+}
+
+func (p *FilePager) ReadPage(id PageID, buf []byte) error {
+	if len(buf) != p.pageSize {
+		return fmt.Errorf("read page %d: buffer size %d, page size %d", id, len(buf), p.pageSize)
+	}
+	n, err := p.f.ReadAt(buf, int64(id)*int64(p.pageSize))
+	if errors.Is(err, io.EOF) { // beyond the end of file
+		for i := n; i < len(buf); i++ {
+			buf[i] = 0
+		}
+		return nil
+	}
+	return err
+}
+
+func (p *FilePager) WritePage(id PageID, buf []byte) error {
+	if len(buf) != p.pageSize {
+		return fmt.Errorf("write page %d: buffer size %d, page size %d", id, len(buf), p.pageSize)
+	}
+	_, err := p.f.WriteAt(buf, int64(id)*int64(p.pageSize))
+	return err
+}
+
+func (p *FilePager) Sync() error {
+	return p.f.Sync()
+}
+
+func (p *FilePager) Close() error {
+	return p.f.Close()
+}
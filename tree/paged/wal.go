@@ -0,0 +1,129 @@
+package paged
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+)
+
+const walMagic = 0x57414c31 // "WAL1"
+
+// WAL is a redo log of full page images.
+// Each transaction is written as
+//
+//	magic uint32 | n uint32 | n * (id uint32 | page) | crc32 uint32
+//
+// A transaction is committed once it's synced with a valid checksum,
+// a torn one at the tail is ignored by Replay.
+type WAL struct {
+	f        *os.File
+	pageSize int
+	off      int64 // end of the last committed transaction
+	pages    int   // number of page images since the last Reset
+}
+
+func OpenWAL(path string, pageSize int) (*WAL, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &WAL{f: f, pageSize: pageSize}, nil
+}
+
+// Append writes pages as one transaction, and syncs it to disk
+func (w *WAL) Append(pages map[PageID][]byte) error {
+	ids := make([]PageID, 0, len(pages))
+	for id := range pages {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	buf := make([]byte, 8+len(pages)*(4+w.pageSize)+4)
+	binary.LittleEndian.PutUint32(buf[0:], walMagic)
+	binary.LittleEndian.PutUint32(buf[4:], uint32(len(pages)))
+	off := 8
+	for _, id := range ids {
+		binary.LittleEndian.PutUint32(buf[off:], uint32(id))
+		copy(buf[off+4:], pages[id])
+		off += 4 + w.pageSize
+	}
+	binary.LittleEndian.PutUint32(buf[off:], crc32.ChecksumIEEE(buf[:off]))
+
+	if _, err := w.f.WriteAt(buf, w.off); err != nil {
+		w.f.Truncate(w.off) // best effort, Replay ignores a torn tail anyway
+		return err
+	}
+	if err := w.f.Sync(); err != nil {
+		w.f.Truncate(w.off) // not durable, so not committed
+		return err
+	}
+	w.off += int64(len(buf))
+	w.pages += len(pages)
+	return nil
+}
+
+// Replay applies page images of committed transactions in order, stops at the first torn one
+func (w *WAL) Replay(apply func(id PageID, page []byte) error) error {
+	w.off, w.pages = 0, 0
+	fi, err := w.f.Stat()
+	if err != nil {
+		return err
+	}
+	head := make([]byte, 8)
+	for {
+		if _, err := w.f.ReadAt(head, w.off); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if binary.LittleEndian.Uint32(head) != walMagic {
+			return nil
+		}
+		n := int64(binary.LittleEndian.Uint32(head[4:]))
+		size := 8 + n*int64(4+w.pageSize) + 4
+		if w.off+size > fi.Size() { // torn, or n of a corrupt header, don't allocate it
+			return nil
+		}
+		body := make([]byte, size)
+		if _, err := w.f.ReadAt(body, w.off); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		end := len(body) - 4
+		if crc32.ChecksumIEEE(body[:end]) != binary.LittleEndian.Uint32(body[end:]) {
+			return nil
+		}
+		for i := 0; i < int(n); i++ {
+			rec := body[8+i*(4+w.pageSize):]
+			if err := apply(PageID(binary.LittleEndian.Uint32(rec)), rec[4:4+w.pageSize]); err != nil {
+				return err
+			}
+		}
+		w.off += int64(len(body))
+		w.pages += int(n)
+	}
+}
+
+// Pages returns the number of page images since the last Reset
+func (w *WAL) Pages() int {
+	return w.pages
+}
+
+// Reset empties the log, call it after all logged pages are synced to the data file
+func (w *WAL) Reset() error {
+	if err := w.f.Truncate(0); err != nil {
+		return err
+	}
+	w.off, w.pages = 0, 0
+	return w.f.Sync()
+}
+
+func (w *WAL) Close() error {
+	return w.f.Close()
+}
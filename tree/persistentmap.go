@@ -0,0 +1,443 @@
+package tree
+
+import (
+	"fmt"
+	"github.com/joexzh/dsa"
+	"github.com/joexzh/dsa/list"
+)
+
+// PersistentMap is an immutable AVL tree map. Put and Remove return a new version and never modify
+// the old one, they copy the o(logn) nodes on the search path and share all the others,
+// so any version is a consistent snapshot, safe to read by goroutines without locks.
+//
+// Nodes have no parent link, which would prevent sharing, so iterators keep a stack of the path.
+// The zero value is an empty map.
+type PersistentMap struct {
+	root *pNode
+}
+
+type pNode struct {
+	entry  dsa.Entry
+	lc     *pNode
+	rc     *pNode
+	height int
+	size   int
+}
+
+func NewPersistentMap() PersistentMap {
+	return PersistentMap{}
+}
+
+func (m PersistentMap) Size() int {
+	return pSize(m.root)
+}
+
+func (m PersistentMap) Empty() bool {
+	return m.root == nil
+}
+
+// Get value of key, nil if not found
+func (m PersistentMap) Get(k dsa.Item) interface{} {
+	if x := m.search(k); x != nil {
+		return x.entry.V
+	}
+	return nil
+}
+
+// Contains returns true if k exists
+func (m PersistentMap) Contains(k dsa.Item) bool {
+	return m.search(k) != nil
+}
+
+// Put returns a new version with the key-value pair, if k already exists, the value is replaced
+func (m PersistentMap) Put(k dsa.Item, v interface{}) PersistentMap {
+	return PersistentMap{root: pInsert(m.root, k, v)}
+}
+
+// Remove returns a new version without k, or m itself if not found
+func (m PersistentMap) Remove(k dsa.Item) PersistentMap {
+	root, ok := pRemove(m.root, k)
+	if !ok {
+		return m
+	}
+	return PersistentMap{root: root}
+}
+
+// First returns the entry of the smallest key, false if empty
+func (m PersistentMap) First() (dsa.Entry, bool) {
+	x := m.root
+	for x != nil && x.lc != nil {
+		x = x.lc
+	}
+	return pEntry(x)
+}
+
+// Last returns the entry of the biggest key, false if empty
+func (m PersistentMap) Last() (dsa.Entry, bool) {
+	x := m.root
+	for x != nil && x.rc != nil {
+		x = x.rc
+	}
+	return pEntry(x)
+}
+
+// Floor returns the entry of the biggest key not greater than k, false if not found
+func (m PersistentMap) Floor(k dsa.Item) (dsa.Entry, bool) {
+	var floor *pNode
+	for x := m.root; x != nil; {
+		if k.Less(x.entry.K) {
+			x = x.lc
+		} else {
+			floor = x
+			x = x.rc
+		}
+	}
+	return pEntry(floor)
+}
+
+// Ceiling returns the entry of the smallest key not less than k, false if not found
+func (m PersistentMap) Ceiling(k dsa.Item) (dsa.Entry, bool) {
+	var ceiling *pNode
+	for x := m.root; x != nil; {
+		if x.entry.K.Less(k) {
+			x = x.rc
+		} else {
+			ceiling = x
+			x = x.lc
+		}
+	}
+	return pEntry(ceiling)
+}
+
+// Rank returns the number of keys less than k, o(logn)
+func (m PersistentMap) Rank(k dsa.Item) int {
+	r := 0
+	for x := m.root; x != nil; {
+		if k.Less(x.entry.K) {
+			x = x.lc
+		} else if x.entry.K.Less(k) {
+			r += pSize(x.lc) + 1
+			x = x.rc
+		} else {
+			return r + pSize(x.lc)
+		}
+	}
+	return r
+}
+
+// Select returns the entry of rank i, false if i is out of [0, size), o(logn)
+func (m PersistentMap) Select(i int) (dsa.Entry, bool) {
+	if i < 0 || i >= m.Size() {
+		return dsa.Entry{}, false
+	}
+	x := m.root
+	for {
+		l := pSize(x.lc)
+		if i < l {
+			x = x.lc
+		} else if i > l {
+			i -= l + 1
+			x = x.rc
+		} else {
+			return x.entry, true
+		}
+	}
+}
+
+// CountRange returns the number of keys in [lo, hi], o(logn)
+func (m PersistentMap) CountRange(lo dsa.Item, hi dsa.Item) int {
+	if hi.Less(lo) {
+		return 0
+	}
+	n := m.Rank(hi) - m.Rank(lo)
+	if m.Contains(hi) {
+		n++
+	}
+	return n
+}
+
+// GetRange by [startK, endK] of key range, returns ordered entries
+func (m PersistentMap) GetRange(startK dsa.Item, endK dsa.Item) list.LinkedList {
+	ents := list.NewLinkedList()
+	for it := m.IterFrom(startK); it.Next() && !endK.Less(it.Entry().K); {
+		ents.InsertEnd(it.Entry())
+	}
+	return ents
+}
+
+// Iter returns an iterator in ascending order from the smallest key
+func (m PersistentMap) Iter() *PersistentIterator {
+	it := &PersistentIterator{}
+	for x := m.root; x != nil; x = x.lc {
+		it.stack = append(it.stack, x)
+	}
+	return it
+}
+
+// IterFrom returns an iterator in ascending order from the smallest key not less than k
+func (m PersistentMap) IterFrom(k dsa.Item) *PersistentIterator {
+	it := &PersistentIterator{}
+	for x := m.root; x != nil; { // keep the nodes not less than k on the path, they are visited later
+		if x.entry.K.Less(k) {
+			x = x.rc
+		} else {
+			it.stack = append(it.stack, x)
+			x = x.lc
+		}
+	}
+	return it
+}
+
+// ReverseIter returns an iterator in descending order from the biggest key
+func (m PersistentMap) ReverseIter() *PersistentIterator {
+	it := &PersistentIterator{reverse: true}
+	for x := m.root; x != nil; x = x.rc {
+		it.stack = append(it.stack, x)
+	}
+	return it
+}
+
+// ReverseIterFrom returns an iterator in descending order from the biggest key not greater than k
+func (m PersistentMap) ReverseIterFrom(k dsa.Item) *PersistentIterator {
+	it := &PersistentIterator{reverse: true}
+	for x := m.root; x != nil; {
+		if k.Less(x.entry.K) {
+			x = x.lc
+		} else {
+			it.stack = append(it.stack, x)
+			x = x.rc
+		}
+	}
+	return it
+}
+
+// Traverse entries in ascending order
+func (m PersistentMap) Traverse(f func(e dsa.Entry)) {
+	var walk func(x *pNode)
+	walk = func(x *pNode) {
+		if x != nil {
+			walk(x.lc)
+			f(x.entry)
+			walk(x.rc)
+		}
+	}
+	walk(m.root)
+}
+
+// Validate checks the key order, heights, sizes and balance factors
+func (m PersistentMap) Validate() error {
+	var last *pNode
+	var check func(x *pNode) error
+	check = func(x *pNode) error {
+		if x == nil {
+			return nil
+		}
+		if err := check(x.lc); err != nil {
+			return err
+		}
+		if last != nil && !last.entry.K.Less(x.entry.K) {
+			return fmt.Errorf("key %v is not less than %v", last.entry.K, x.entry.K)
+		}
+		last = x
+		if err := check(x.rc); err != nil {
+			return err
+		}
+		y := *x
+		y.update()
+		if y.height != x.height || y.size != x.size {
+			return fmt.Errorf("node %v expected height %d size %d, got %d %d", x.entry, y.height, y.size, x.height, x.size)
+		}
+		if bf := pHeight(x.lc) - pHeight(x.rc); bf < -1 || bf > 1 {
+			return fmt.Errorf("node %v is unbalanced, balance factor %d", x.entry, bf)
+		}
+		return nil
+	}
+	return check(m.root)
+}
+
+func (m PersistentMap) search(k dsa.Item) *pNode {
+	x := m.root
+	for x != nil {
+		if k.Less(x.entry.K) {
+			x = x.lc
+		} else if x.entry.K.Less(k) {
+			x = x.rc
+		} else {
+			break
+		}
+	}
+	return x
+}
+
+// PersistentIterator walks entries of a version in order, the stack holds the nodes to visit,
+// the top is the next one.
+//
+//	for it := m.Iter(); it.Next(); {
+//		e := it.Entry()
+//	}
+type PersistentIterator struct {
+	stack   []*pNode
+	curr    *pNode
+	reverse bool
+}
+
+// Next moves to the next entry, returns false if there is no more
+func (it *PersistentIterator) Next() bool {
+	if len(it.stack) == 0 {
+		it.curr = nil
+		return false
+	}
+	it.curr = it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+	if it.reverse { // the rightmost branch of the left subtree goes next
+		for x := it.curr.lc; x != nil; x = x.rc {
+			it.stack = append(it.stack, x)
+		}
+	} else {
+		for x := it.curr.rc; x != nil; x = x.lc {
+			it.stack = append(it.stack, x)
+		}
+	}
+	return true
+}
+
+// Entry returns the current entry
+func (it *PersistentIterator) Entry() dsa.Entry {
+	return it.curr.entry
+}
+
+// pInsert returns the new root of subtree x with k, by copying the path
+func pInsert(x *pNode, k dsa.Item, v interface{}) *pNode {
+	if x == nil {
+		return &pNode{entry: dsa.Entry{K: k, V: v}, size: 1}
+	}
+	n := *x
+	if k.Less(x.entry.K) {
+		n.lc = pInsert(x.lc, k, v)
+	} else if x.entry.K.Less(k) {
+		n.rc = pInsert(x.rc, k, v)
+	} else {
+		n.entry.V = v
+		return &n
+	}
+	return pBalance(&n)
+}
+
+// pRemove returns the new root of subtree x without k, and false if k is not found
+func pRemove(x *pNode, k dsa.Item) (*pNode, bool) {
+	if x == nil {
+		return nil, false
+	}
+	n := *x
+	if k.Less(x.entry.K) {
+		lc, ok := pRemove(x.lc, k)
+		if !ok {
+			return x, false
+		}
+		n.lc = lc
+	} else if x.entry.K.Less(k) {
+		rc, ok := pRemove(x.rc, k)
+		if !ok {
+			return x, false
+		}
+		n.rc = rc
+	} else {
+		if x.lc == nil {
+			return x.rc, true
+		}
+		if x.rc == nil {
+			return x.lc, true
+		}
+		var succ *pNode // the successor takes the place of x
+		n.rc, succ = pRemoveMin(x.rc)
+		n.entry = succ.entry
+	}
+	return pBalance(&n), true
+}
+
+// pRemoveMin returns the new root of subtree x without its smallest node, and the smallest node
+func pRemoveMin(x *pNode) (*pNode, *pNode) {
+	if x.lc == nil {
+		return x.rc, x
+	}
+	n := *x
+	var min *pNode
+	n.lc, min = pRemoveMin(x.lc)
+	return pBalance(&n), min
+}
+
+// pBalance updates the new node x, and rotates if unbalanced, returns the root of the subtree.
+// x must be a copy owned by the caller, shared children are copied before rotation.
+func pBalance(x *pNode) *pNode {
+	bf := pHeight(x.lc) - pHeight(x.rc)
+	if bf > 1 {
+		if pHeight(x.lc.lc) < pHeight(x.lc.rc) {
+			x.lc = pRotateLeft(pCopy(x.lc))
+		}
+		return pRotateRight(x)
+	}
+	if bf < -1 {
+		if pHeight(x.rc.rc) < pHeight(x.rc.lc) {
+			x.rc = pRotateRight(pCopy(x.rc))
+		}
+		return pRotateLeft(x)
+	}
+	x.update()
+	return x
+}
+
+// pRotateRight lifts a copy of the left child of x, x must be owned by the caller
+func pRotateRight(x *pNode) *pNode {
+	l := pCopy(x.lc)
+	x.lc = l.rc
+	x.update()
+	l.rc = x
+	l.update()
+	return l
+}
+
+// pRotateLeft lifts a copy of the right child of x, x must be owned by the caller
+func pRotateLeft(x *pNode) *pNode {
+	r := pCopy(x.rc)
+	x.rc = r.lc
+	x.update()
+	r.lc = x
+	r.update()
+	return r
+}
+
+func pCopy(x *pNode) *pNode {
+	n := *x
+	return &n
+}
+
+func (x *pNode) update() {
+	l, r := pHeight(x.lc), pHeight(x.rc)
+	if l > r {
+		x.height = 1 + l
+	} else {
+		x.height = 1 + r
+	}
+	x.size = pSize(x.lc) + 1 + pSize(x.rc)
+}
+
+func pHeight(x *pNode) int {
+	if x == nil {
+		return -1
+	}
+	return x.height
+}
+
+func pSize(x *pNode) int {
+	if x == nil {
+		return 0
+	}
+	return x.size
+}
+
+func pEntry(x *pNode) (dsa.Entry, bool) {
+	if x == nil {
+		return dsa.Entry{}, false
+	}
+	return x.entry, true
+}
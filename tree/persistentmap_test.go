@@ -0,0 +1,168 @@
+package tree
+
+import (
+	"github.com/joexzh/dsa"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func checkVersion(t *testing.T, pm PersistentMap, m map[int]int) {
+	if err := pm.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if pm.Size() != len(m) {
+		t.Fatalf("expected size %d, got %d", len(m), pm.Size())
+	}
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	got := make([]int, 0, len(m))
+	pm.Traverse(func(e dsa.Entry) {
+		k := int(e.K.(dsa.Int64))
+		if e.V != m[k] {
+			t.Fatalf("key %d expected value %d, got %v", k, m[k], e.V)
+		}
+		got = append(got, k)
+	})
+	if !reflect.DeepEqual(keys, got) {
+		t.Fatalf("expected keys %v, got %v", keys, got)
+	}
+}
+
+func TestPersistentMap_Versions(t *testing.T) {
+	pm := NewPersistentMap()
+	m := make(map[int]int)
+	versions := []PersistentMap{pm}
+	snapshots := []map[int]int{{}}
+	for i, k := range randomKeys(2000, 500) {
+		if i%3 == 2 {
+			pm = pm.Remove(dsa.Int64(k))
+			delete(m, k)
+		} else {
+			pm = pm.Put(dsa.Int64(k), i)
+			m[k] = i
+		}
+		if i%100 == 0 {
+			versions = append(versions, pm)
+			cp := make(map[int]int, len(m))
+			for k, v := range m {
+				cp[k] = v
+			}
+			snapshots = append(snapshots, cp)
+		}
+	}
+	checkVersion(t, pm, m)
+	for i, v := range versions { // old versions are not affected by later ones
+		checkVersion(t, v, snapshots[i])
+	}
+	if same := pm.Remove(dsa.Int64(-1)); same.root != pm.root {
+		t.Fatalf("expected the same version removing a missing key")
+	}
+}
+
+func TestPersistentMap_Navigation(t *testing.T) {
+	pm := NewPersistentMap()
+	keys := sortedUnique(randomKeys(300, 1000))
+	for _, k := range keys {
+		pm = pm.Put(dsa.Int64(k), k)
+	}
+	for q := -1; q <= 1001; q++ {
+		i := sort.SearchInts(keys, q) // keys[i] is the ceiling
+		if e, ok := pm.Ceiling(dsa.Int64(q)); ok != (i < len(keys)) || (ok && e.K != dsa.Int64(keys[i])) {
+			t.Fatalf("ceiling %d got %v %v", q, e, ok)
+		}
+		f := i - 1
+		if i < len(keys) && keys[i] == q {
+			f = i
+		}
+		if e, ok := pm.Floor(dsa.Int64(q)); ok != (f >= 0) || (ok && e.K != dsa.Int64(keys[f])) {
+			t.Fatalf("floor %d got %v %v", q, e, ok)
+		}
+		if r := pm.Rank(dsa.Int64(q)); r != i {
+			t.Fatalf("rank %d expected %d, got %d", q, i, r)
+		}
+
+		expected := make([]int, 0)
+		for j := i; j < len(keys) && j < i+5; j++ {
+			expected = append(expected, keys[j])
+		}
+		got := make([]int, 0)
+		for it := pm.IterFrom(dsa.Int64(q)); len(got) < 5 && it.Next(); {
+			got = append(got, int(it.Entry().K.(dsa.Int64)))
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Fatalf("iter from %d expected %v, got %v", q, expected, got)
+		}
+		expected = expected[:0]
+		for j := f; j >= 0 && j > f-5; j-- {
+			expected = append(expected, keys[j])
+		}
+		got = got[:0]
+		for it := pm.ReverseIterFrom(dsa.Int64(q)); len(got) < 5 && it.Next(); {
+			got = append(got, int(it.Entry().K.(dsa.Int64)))
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Fatalf("reverse iter from %d expected %v, got %v", q, expected, got)
+		}
+	}
+	for i, k := range keys {
+		if e, ok := pm.Select(i); !ok || e.K != dsa.Int64(k) {
+			t.Fatalf("select %d expected %d, got %v", i, k, e)
+		}
+	}
+	if first, _ := pm.First(); first.K != dsa.Int64(keys[0]) {
+		t.Fatalf("expected first %d, got %v", keys[0], first.K)
+	}
+	if last, _ := pm.Last(); last.K != dsa.Int64(keys[len(keys)-1]) {
+		t.Fatalf("expected last %d, got %v", keys[len(keys)-1], last.K)
+	}
+	ents := pm.GetRange(dsa.Int64(100), dsa.Int64(500))
+	if n := pm.CountRange(dsa.Int64(100), dsa.Int64(500)); ents.Size() != n {
+		t.Fatalf("expected %d entries in range, got %d", n, ents.Size())
+	}
+}
+
+// B/op is the memory overhead of each version, with all versions kept alive
+func BenchmarkPersistentMap_PutVersion(b *testing.B) {
+	keys := benchKeys()
+	pm := NewPersistentMap()
+	for _, k := range keys {
+		pm = pm.Put(k, k)
+	}
+	versions := make([]PersistentMap, 0, b.N)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pm = pm.Put(dsa.Int64(i), i)
+		versions = append(versions, pm)
+	}
+}
+
+func BenchmarkPersistentMap_RemoveVersion(b *testing.B) {
+	keys := benchKeys()
+	pm := NewPersistentMap()
+	for _, k := range keys {
+		pm = pm.Put(k, k)
+	}
+	versions := make([]PersistentMap, 0, b.N)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		versions = append(versions, pm.Remove(keys[i%len(keys)])) // each from the same base
+	}
+}
+
+func BenchmarkPersistentMap_Put(b *testing.B) {
+	keys := benchKeys()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pm := NewPersistentMap()
+		for _, k := range keys {
+			pm = pm.Put(k, k)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package tree
+
+import (
+	"fmt"
+	"github.com/joexzh/dsa"
+	"sort"
+	"strings"
+)
+
+// RadixTree is a compressed Trie, a node with only one child and no key ending there is merged
+// into its child, so each edge is labeled by a string, and there are at most 2n nodes for n keys.
+// Children are kept sorted by the first byte of labels for ordered iteration.
+// Entries passed to callbacks have dsa.String keys.
+type RadixTree struct {
+	root radixNode // its label is always empty
+	size int
+}
+
+type radixNode struct {
+	label string       // the edge from parent
+	child []*radixNode // sorted by label[0]
+	has   bool         // a key ends here
+	value interface{}
+}
+
+func NewRadixTree() RadixTree {
+	return RadixTree{}
+}
+
+func (t *RadixTree) Size() int {
+	return t.size
+}
+
+func (t *RadixTree) Empty() bool {
+	return t.size <= 0
+}
+
+// Get value of key, nil if not found
+func (t *RadixTree) Get(key string) interface{} {
+	if x, rest := t.find(key); x != nil && rest == "" && x.has {
+		return x.value
+	}
+	return nil
+}
+
+// Contains returns true if key exists
+func (t *RadixTree) Contains(key string) bool {
+	x, rest := t.find(key)
+	return x != nil && rest == "" && x.has
+}
+
+// Put inserts a key-value pair. If key already exists, replaces the value and returns false.
+func (t *RadixTree) Put(key string, v interface{}) bool {
+	x := &t.root
+	for {
+		if key == "" {
+			x.value = v
+			if x.has {
+				return false
+			}
+			x.has = true
+			t.size++
+			return true
+		}
+		i, ok := x.search(key[0])
+		if !ok {
+			x.insert(i, &radixNode{label: key, has: true, value: v})
+			t.size++
+			return true
+		}
+		c := x.child[i]
+		n := commonPrefix(c.label, key)
+		if n < len(c.label) { // split the edge at n
+			mid := &radixNode{label: c.label[:n], child: []*radixNode{c}}
+			c.label = c.label[n:]
+			x.child[i] = mid
+			c = mid
+		}
+		x, key = c, key[n:]
+	}
+}
+
+// Remove key, returns false if not found. The node left with one child and no key is merged into it.
+func (t *RadixTree) Remove(key string) bool {
+	var parent *radixNode
+	x := &t.root
+	for key != "" {
+		i, ok := x.search(key[0])
+		if !ok || !strings.HasPrefix(key, x.child[i].label) {
+			return false
+		}
+		parent, x, key = x, x.child[i], key[len(x.child[i].label):]
+	}
+	if !x.has {
+		return false
+	}
+	x.has, x.value = false, nil
+	t.size--
+	if parent == nil { // the root
+		return true
+	}
+	switch len(x.child) {
+	case 0:
+		i, _ := parent.search(x.label[0])
+		parent.remove(i)
+		if parent != &t.root && !parent.has && len(parent.child) == 1 {
+			parent.mergeChild()
+		}
+	case 1:
+		x.mergeChild()
+	}
+	return true
+}
+
+// HasPrefix returns true if any key starts with prefix
+func (t *RadixTree) HasPrefix(prefix string) bool {
+	x, _ := t.findPrefix(prefix)
+	return x != nil && (x.has || len(x.child) > 0)
+}
+
+// KeysWithPrefix returns keys starting with prefix in ascending order
+func (t *RadixTree) KeysWithPrefix(prefix string) []string {
+	keys := make([]string, 0)
+	t.TraversePrefix(prefix, func(e dsa.Entry) {
+		keys = append(keys, string(e.K.(dsa.String)))
+	})
+	return keys
+}
+
+// TraversePrefix traverses entries whose key starts with prefix in ascending order
+func (t *RadixTree) TraversePrefix(prefix string, f func(e dsa.Entry)) {
+	if x, path := t.findPrefix(prefix); x != nil {
+		x.traverse([]byte(path), f)
+	}
+}
+
+// Traverse entries in ascending order
+func (t *RadixTree) Traverse(f func(e dsa.Entry)) {
+	t.root.traverse(nil, f)
+}
+
+// LongestPrefixOf returns the entry of the longest key which is a prefix of s, false if not found
+func (t *RadixTree) LongestPrefixOf(s string) (dsa.Entry, bool) {
+	x := &t.root
+	n := -1 // length of the longest key found
+	var v interface{}
+	for i := 0; ; {
+		if x.has {
+			n, v = i, x.value
+		}
+		if i == len(s) {
+			break
+		}
+		j, ok := x.search(s[i])
+		if !ok || !strings.HasPrefix(s[i:], x.child[j].label) {
+			break
+		}
+		x = x.child[j]
+		i += len(x.label)
+	}
+	if n < 0 {
+		return dsa.Entry{}, false
+	}
+	return dsa.Entry{K: dsa.String(s[:n]), V: v}, true
+}
+
+// Validate checks every node except root has a key or at least 2 children, labels are not empty
+// and sorted by the first byte, and the size
+func (t *RadixTree) Validate() error {
+	n := 0
+	var check func(x *radixNode, path string) error
+	check = func(x *radixNode, path string) error {
+		if x.has {
+			n++
+		}
+		if x != &t.root {
+			if x.label == "" {
+				return fmt.Errorf("node %q has empty label", path)
+			}
+			if !x.has && len(x.child) < 2 {
+				return fmt.Errorf("node %q has no key and %d children", path, len(x.child))
+			}
+		}
+		for i, c := range x.child {
+			if c.label != "" && i > 0 && x.child[i-1].label[0] >= c.label[0] {
+				return fmt.Errorf("node %q has unsorted children", path)
+			}
+			if err := check(c, path+c.label); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := check(&t.root, ""); err != nil {
+		return err
+	}
+	if n != t.size {
+		return fmt.Errorf("expected size %d, got %d", n, t.size)
+	}
+	return nil
+}
+
+// find goes down along key as far as whole edges match, returns the last node and the rest of key
+func (t *RadixTree) find(key string) (*radixNode, string) {
+	x := &t.root
+	for key != "" {
+		i, ok := x.search(key[0])
+		if !ok || !strings.HasPrefix(key, x.child[i].label) {
+			break
+		}
+		x, key = x.child[i], key[len(x.child[i].label):]
+	}
+	return x, key
+}
+
+// findPrefix returns the highest node whose path starts with prefix, and its path, nil if not found
+func (t *RadixTree) findPrefix(prefix string) (*radixNode, string) {
+	x, rest := t.find(prefix)
+	path := prefix[:len(prefix)-len(rest)]
+	if rest == "" {
+		return x, path
+	}
+	i, ok := x.search(rest[0]) // prefix may end in the middle of an edge
+	if !ok || !strings.HasPrefix(x.child[i].label, rest) {
+		return nil, ""
+	}
+	return x.child[i], path + x.child[i].label
+}
+
+// mergeChild merges the only child into x
+func (x *radixNode) mergeChild() {
+	c := x.child[0]
+	x.label += c.label
+	x.child, x.has, x.value = c.child, c.has, c.value
+}
+
+// search returns the index of the child whose label starts with c, or where it should be inserted and false
+func (x *radixNode) search(c byte) (int, bool) {
+	i := sort.Search(len(x.child), func(i int) bool {
+		return x.child[i].label[0] >= c
+	})
+	return i, i < len(x.child) && x.child[i].label[0] == c
+}
+
+func (x *radixNode) insert(i int, child *radixNode) {
+	x.child = append(x.child, nil)
+	copy(x.child[i+1:], x.child[i:])
+	x.child[i] = child
+}
+
+func (x *radixNode) remove(i int) {
+	copy(x.child[i:], x.child[i+1:])
+	x.child[len(x.child)-1] = nil
+	x.child = x.child[:len(x.child)-1]
+}
+
+// traverse the subtree x in pre-order, which is the ascending order of keys, path is the keys to x
+func (x *radixNode) traverse(path []byte, f func(e dsa.Entry)) {
+	if x.has {
+		f(dsa.Entry{K: dsa.String(path), V: x.value})
+	}
+	for _, c := range x.child {
+		c.traverse(append(path, c.label...), f)
+	}
+}
+
+func commonPrefix(a string, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,35 @@
+package tree
+
+import (
+	"testing"
+)
+
+func TestRadixTree(t *testing.T) {
+	rt := NewRadixTree()
+	testStringTree(t, "RadixTree", &rt)
+}
+
+func TestRadixTree_Routes(t *testing.T) {
+	rt := NewRadixTree()
+	for _, r := range []string{"/", "/api", "/api/v1", "/api/v1/users", "/static"} {
+		rt.Put(r, r)
+	}
+	for q, expected := range map[string]string{
+		"/api/v1/users/7": "/api/v1/users",
+		"/api/v2":         "/api",
+		"/apix":           "/api",
+		"/static/a.css":   "/static",
+		"/index.html":     "/",
+	} {
+		if e, ok := rt.LongestPrefixOf(q); !ok || e.V != expected {
+			t.Fatalf("route %q expected %q, got %v", q, expected, e.V)
+		}
+	}
+	rt.Remove("/api")
+	if err := rt.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := rt.LongestPrefixOf("/api/v2"); e.V != "/" {
+		t.Fatalf("expected / after removing /api, got %v", e.V)
+	}
+}
@@ -1 +1,199 @@
 package tree
+
+import (
+	"fmt"
+	"github.com/joexzh/dsa"
+	"github.com/joexzh/dsa/dict"
+)
+
+var _ dict.Dictionary = (*RBTree)(nil)
+
+// RBTree is a red-black tree, the default ordered map.
+// Each Put does at most 2 rotations, and each Remove at most 3.
+// The height field of BinNode is the black height here, -1 for nil.
+type RBTree struct {
+	BST
+}
+
+func NewRBTree() RBTree {
+	t := RBTree{BST: NewBST()}
+	t.updateHeight = rbUpdateHeight
+	return t
+}
+
+// Put inserts a key-value pair. If k already exists, replaces the value and returns false.
+func (t *RBTree) Put(k dsa.Item, v interface{}) bool {
+	x, ok := t.insert(k, v) // new node is red with black height -1
+	if ok {
+		t.solveDoubleRed(x)
+	}
+	return ok
+}
+
+// Remove the node of key k, returns false if not found
+func (t *RBTree) Remove(k dsa.Item) bool {
+	xp := t.search(k)
+	if *xp == nil {
+		return false
+	}
+	r := t.removeAt(xp)
+	t.size--
+	t.augmentAbove(t.hot) // black heights are fixed below, rotations keep sizes and augmented values
+	if t.size == 0 {
+		return true
+	}
+	if t.hot == nil { // removed the root, r is the new root
+		t.root.color = black
+		t.update(t.root)
+		return true
+	}
+	if blackHeightUpdated(t.hot) { // black height of ancestors are not affected
+		return true
+	}
+	if isRed(r) { // the removed one is black, r can take its place by turning black
+		r.color = black
+		r.height++
+		return true
+	}
+	t.solveDoubleBlack(r)
+	return true
+}
+
+// Validate checks the BST properties, black heights, and no red node has a red child
+func (t *RBTree) Validate() error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+	if isRed(t.root) {
+		return fmt.Errorf("root %v is red", t.root.entry)
+	}
+	var err error
+	t.root.TravPost(func(x *BinNode) {
+		if err != nil {
+			return
+		}
+		if isRed(x) && (isRed(x.lc) || isRed(x.rc)) {
+			err = fmt.Errorf("red node %v has red child", x.entry)
+		} else if stature(x.lc) != stature(x.rc) {
+			err = fmt.Errorf("node %v has different black height %d and %d", x.entry, stature(x.lc), stature(x.rc))
+		}
+	})
+	return err
+}
+
+// solveDoubleRed fixes x and its parent are both red
+func (t *RBTree) solveDoubleRed(x *BinNode) {
+	if x.isRoot() {
+		x.color = black
+		x.height++
+		return
+	}
+	p := x.parent
+	if isBlack(p) {
+		return
+	}
+	g := p.parent // p is red, so g exists
+	u := x.uncle()
+	if isBlack(u) { // RR-1: recolor and rotate once, done
+		if x.isLChild() == p.isLChild() {
+			p.color = black
+		} else {
+			x.color = black
+		}
+		g.color = red
+		t.rotateAt(x)
+		return
+	}
+	// RR-2: recolor, the double red may go up to g
+	p.color = black
+	p.height++
+	u.color = black
+	u.height++
+	if !g.isRoot() {
+		g.color = red
+	}
+	t.solveDoubleRed(g)
+}
+
+// solveDoubleBlack fixes the black height of r is one less than its sibling.
+// r may be nil, then t.hot is its parent.
+func (t *RBTree) solveDoubleBlack(r *BinNode) {
+	p := t.hot
+	if r != nil {
+		p = r.parent
+	}
+	if p == nil {
+		return
+	}
+	s := p.lc
+	if r == p.lc {
+		s = p.rc
+	}
+	if isRed(s) { // BB-3: rotate to make the sibling black, then go BB-1 or BB-2R
+		s.color = black
+		p.color = red
+		v := s.rc
+		if s.isLChild() {
+			v = s.lc
+		}
+		t.hot = p
+		t.rotateAt(v)
+		t.solveDoubleBlack(r)
+		return
+	}
+
+	var v *BinNode // red child of s
+	if isRed(s.rc) {
+		v = s.rc
+	}
+	if isRed(s.lc) {
+		v = s.lc
+	}
+	if v != nil { // BB-1: rotate once, done
+		oldColor := p.color
+		b := t.rotateAt(v)
+		if b.lc != nil {
+			b.lc.color = black
+			t.updateHeight(b.lc)
+		}
+		if b.rc != nil {
+			b.rc.color = black
+			t.updateHeight(b.rc)
+		}
+		b.color = oldColor
+		t.updateHeight(b)
+		return
+	}
+
+	s.color = red // BB-2: recolor
+	s.height--
+	if isRed(p) { // BB-2R, done
+		p.color = black
+		return
+	}
+	p.height-- // BB-2B, p becomes the double black one
+	t.solveDoubleBlack(p)
+}
+
+// blackHeightUpdated returns true if the black height of x is consistent with its children
+func blackHeightUpdated(x *BinNode) bool {
+	if stature(x.lc) != stature(x.rc) {
+		return false
+	}
+	if isRed(x) {
+		return x.height == stature(x.lc)
+	}
+	return x.height == stature(x.lc)+1
+}
+
+func rbUpdateHeight(x *BinNode) {
+	l, r := stature(x.lc), stature(x.rc)
+	if l > r {
+		x.height = l
+	} else {
+		x.height = r
+	}
+	if isBlack(x) {
+		x.height++
+	}
+}
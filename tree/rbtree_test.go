@@ -0,0 +1,211 @@
+package tree
+
+import (
+	"github.com/joexzh/dsa"
+	"github.com/joexzh/dsa/dict"
+	"github.com/joexzh/dsa/list"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestRBTree_PutRemove(t *testing.T) {
+	rb := NewRBTree()
+	m := make(map[int]int)
+	for i, k := range randomKeys(1000, 700) {
+		_, exist := m[k]
+		if ok := rb.Put(dsa.Int64(k), i); ok == exist {
+			t.Fatalf("put key %d expected %v, got %v", k, !exist, ok)
+		}
+		m[k] = i
+		if err := rb.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for k, v := range m {
+		if got := rb.Get(dsa.Int64(k)); got != v {
+			t.Fatalf("key %d expected %v, got %v", k, v, got)
+		}
+	}
+
+	for _, k := range randomKeys(2000, 700) {
+		_, exist := m[k]
+		if ok := rb.Remove(dsa.Int64(k)); ok != exist {
+			t.Fatalf("remove key %d expected %v, got %v", k, exist, ok)
+		}
+		delete(m, k)
+		if err := rb.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rb.Size() != len(m) {
+		t.Fatalf("expected size %d, got %d", len(m), rb.Size())
+	}
+}
+
+func TestRBTree_FloorCeiling(t *testing.T) {
+	rb := NewRBTree()
+	for _, k := range []int{10, 20, 30, 40} {
+		rb.Put(dsa.Int64(k), k)
+	}
+	cases := []struct {
+		k       int
+		floor   interface{}
+		ceiling interface{}
+	}{
+		{5, nil, 10},
+		{10, 10, 10},
+		{25, 20, 30},
+		{40, 40, 40},
+		{45, 40, nil},
+	}
+	value := func(x *BinNode) interface{} {
+		if x == nil {
+			return nil
+		}
+		return x.Value()
+	}
+	for _, c := range cases {
+		if got := value(rb.Floor(dsa.Int64(c.k))); got != c.floor {
+			t.Fatalf("floor of %d expected %v, got %v", c.k, c.floor, got)
+		}
+		if got := value(rb.Ceiling(dsa.Int64(c.k))); got != c.ceiling {
+			t.Fatalf("ceiling of %d expected %v, got %v", c.k, c.ceiling, got)
+		}
+	}
+}
+
+func TestRBTree_RangeIter(t *testing.T) {
+	rb := NewRBTree()
+	keys := randomKeys(300, 1000)
+	for _, k := range keys {
+		rb.Put(dsa.Int64(k), k)
+	}
+	expected := make([]int, 0)
+	for _, k := range sortedUnique(keys) {
+		if 200 <= k && k <= 700 {
+			expected = append(expected, k)
+		}
+	}
+
+	got := make([]int, 0)
+	rl := rb.GetRange(dsa.Int64(200), dsa.Int64(700))
+	rl.Traverse(func(nd *list.LinkedNode) {
+		got = append(got, int(nd.Data.(dsa.Entry).K.(dsa.Int64)))
+	})
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("GetRange expected %v, got %v", expected, got)
+	}
+
+	got = got[:0]
+	for it := rb.IterFrom(dsa.Int64(200)); it.Next() && it.Node().Key().Less(dsa.Int64(701)); {
+		got = append(got, int(it.Node().Key().(dsa.Int64)))
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("IterFrom expected %v, got %v", expected, got)
+	}
+
+	got = got[:0]
+	for it := rb.ReverseIterFrom(dsa.Int64(700)); it.Next() && !it.Node().Key().Less(dsa.Int64(200)); {
+		got = append([]int{int(it.Node().Key().(dsa.Int64))}, got...)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("ReverseIterFrom expected %v, got %v", expected, got)
+	}
+
+	n := 0
+	for it := rb.Iter(); it.Next(); {
+		n++
+	}
+	for it := rb.ReverseIter(); it.Next(); {
+		n--
+	}
+	if n != 0 {
+		t.Fatalf("expected Iter and ReverseIter visit the same number of nodes")
+	}
+}
+
+const benchSize = 1 << 16
+
+func benchKeys() []dsa.Int64 {
+	r := rand.New(rand.NewSource(1))
+	keys := make([]dsa.Int64, benchSize)
+	for i := range keys {
+		keys[i] = dsa.Int64(r.Int63())
+	}
+	return keys
+}
+
+func BenchmarkRBTree_Put(b *testing.B) {
+	keys := benchKeys()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rb := NewRBTree()
+		for _, k := range keys {
+			rb.Put(k, k)
+		}
+	}
+}
+
+func BenchmarkSkipList_Put(b *testing.B) {
+	keys := benchKeys()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sl := dict.NewSkipList()
+		for _, k := range keys {
+			sl.Replace(k, k)
+		}
+	}
+}
+
+func BenchmarkRBTree_Get(b *testing.B) {
+	keys := benchKeys()
+	rb := NewRBTree()
+	for _, k := range keys {
+		rb.Put(k, k)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rb.Get(keys[i%benchSize])
+	}
+}
+
+func BenchmarkSkipList_Get(b *testing.B) {
+	keys := benchKeys()
+	sl := dict.NewSkipList()
+	for _, k := range keys {
+		sl.Replace(k, k)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sl.Get(keys[i%benchSize])
+	}
+}
+
+func BenchmarkRBTree_PutRemove(b *testing.B) {
+	keys := benchKeys()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rb := NewRBTree()
+		for _, k := range keys {
+			rb.Put(k, k)
+		}
+		for _, k := range keys {
+			rb.Remove(k)
+		}
+	}
+}
+
+func BenchmarkSkipList_PutRemove(b *testing.B) {
+	keys := benchKeys()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sl := dict.NewSkipList()
+		for _, k := range keys {
+			sl.Replace(k, k)
+		}
+		for _, k := range keys {
+			sl.Remove(k)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package tree
+
+// SegmentOps defines the aggregate of a SegmentTree.
+//
+// Combine must be associative. Apply and Compose are only needed by range updates:
+// Apply returns the aggregate of n elements agg after applying the update tag to each of them,
+// and Compose returns the tag equivalent to applying old and then tag.
+// Tags must not be nil, which means no pending update.
+type SegmentOps struct {
+	Combine func(a interface{}, b interface{}) interface{}
+	Apply   func(agg interface{}, tag interface{}, n int) interface{}
+	Compose func(old interface{}, tag interface{}) interface{}
+}
+
+// SegmentTree keeps the aggregates of ranges of a sequence, by a complete binary tree
+// whose leaves are the elements and each node is the Combine of its children.
+// Query, Set and Update are o(logn), ranges are half-open [l, r).
+// Range updates are lazy, a tag is kept in the node covered, and pushed down when visited.
+type SegmentTree struct {
+	n   int
+	agg []interface{} // node x has children 2x and 2x+1, root is 1
+	tag []interface{} // pending update of the children of node x
+	ops SegmentOps
+}
+
+func NewSegmentTree(vals []interface{}, ops SegmentOps) SegmentTree {
+	if ops.Combine == nil {
+		panic("SegmentTree: Combine is nil")
+	}
+	t := SegmentTree{n: len(vals), ops: ops}
+	if t.n > 0 {
+		t.agg = make([]interface{}, 4*t.n)
+		t.tag = make([]interface{}, 4*t.n)
+		t.build(1, 0, t.n, vals)
+	}
+	return t
+}
+
+func (t *SegmentTree) Len() int {
+	return t.n
+}
+
+// Query returns the Combine of elements in [l, r), panics if the range is empty or out of bound
+func (t *SegmentTree) Query(l int, r int) interface{} {
+	t.checkRange(l, r)
+	return t.query(1, 0, t.n, l, r)
+}
+
+// Get returns the element i
+func (t *SegmentTree) Get(i int) interface{} {
+	return t.Query(i, i+1)
+}
+
+// Set the element i to v
+func (t *SegmentTree) Set(i int, v interface{}) {
+	t.checkRange(i, i+1)
+	t.set(1, 0, t.n, i, v)
+}
+
+// Update applies tag to every element in [l, r), panics if Apply or Compose is nil
+func (t *SegmentTree) Update(l int, r int, tag interface{}) {
+	if t.ops.Apply == nil || t.ops.Compose == nil {
+		panic("SegmentTree.Update: Apply or Compose is nil")
+	}
+	t.checkRange(l, r)
+	t.update(1, 0, t.n, l, r, tag)
+}
+
+func (t *SegmentTree) checkRange(l int, r int) {
+	if l < 0 || r > t.n || l >= r {
+		panic("SegmentTree: range out of bound or empty")
+	}
+}
+
+func (t *SegmentTree) build(x int, l int, r int, vals []interface{}) {
+	if r-l == 1 {
+		t.agg[x] = vals[l]
+		return
+	}
+	mid := (l + r) / 2
+	t.build(2*x, l, mid, vals)
+	t.build(2*x+1, mid, r, vals)
+	t.agg[x] = t.ops.Combine(t.agg[2*x], t.agg[2*x+1])
+}
+
+func (t *SegmentTree) query(x int, l int, r int, ql int, qr int) interface{} {
+	if ql <= l && r <= qr {
+		return t.agg[x]
+	}
+	mid := (l + r) / 2
+	t.pushDown(x, l, mid, r)
+	if qr <= mid {
+		return t.query(2*x, l, mid, ql, qr)
+	}
+	if ql >= mid {
+		return t.query(2*x+1, mid, r, ql, qr)
+	}
+	return t.ops.Combine(t.query(2*x, l, mid, ql, qr), t.query(2*x+1, mid, r, ql, qr))
+}
+
+func (t *SegmentTree) set(x int, l int, r int, i int, v interface{}) {
+	if r-l == 1 {
+		t.agg[x] = v
+		return
+	}
+	mid := (l + r) / 2
+	t.pushDown(x, l, mid, r)
+	if i < mid {
+		t.set(2*x, l, mid, i, v)
+	} else {
+		t.set(2*x+1, mid, r, i, v)
+	}
+	t.agg[x] = t.ops.Combine(t.agg[2*x], t.agg[2*x+1])
+}
+
+func (t *SegmentTree) update(x int, l int, r int, ql int, qr int, tag interface{}) {
+	if ql <= l && r <= qr {
+		t.applyAt(x, l, r, tag)
+		return
+	}
+	mid := (l + r) / 2
+	t.pushDown(x, l, mid, r)
+	if ql < mid {
+		t.update(2*x, l, mid, ql, qr, tag)
+	}
+	if qr > mid {
+		t.update(2*x+1, mid, r, ql, qr, tag)
+	}
+	t.agg[x] = t.ops.Combine(t.agg[2*x], t.agg[2*x+1])
+}
+
+// applyAt applies tag to node x of range [l, r), and keeps it for the children
+func (t *SegmentTree) applyAt(x int, l int, r int, tag interface{}) {
+	t.agg[x] = t.ops.Apply(t.agg[x], tag, r-l)
+	if r-l == 1 {
+		return
+	}
+	if t.tag[x] == nil {
+		t.tag[x] = tag
+	} else {
+		t.tag[x] = t.ops.Compose(t.tag[x], tag)
+	}
+}
+
+// pushDown applies the pending tag of node x to its children [l, mid) and [mid, r)
+func (t *SegmentTree) pushDown(x int, l int, mid int, r int) {
+	if t.tag[x] == nil {
+		return
+	}
+	t.applyAt(2*x, l, mid, t.tag[x])
+	t.applyAt(2*x+1, mid, r, t.tag[x])
+	t.tag[x] = nil
+}
+
+// SegmentTree2D keeps the aggregates of rectangles of a matrix, by a segment tree of rows
+// whose nodes are segment trees of columns. Query and Set are o(logn*logm), ranges are half-open.
+// Combine must be commutative besides associative, range updates are not supported.
+type SegmentTree2D struct {
+	n, m    int
+	agg     [][]interface{} // bottom-up layout, leaves of rows at [n, 2n), of columns at [m, 2m)
+	combine func(a interface{}, b interface{}) interface{}
+}
+
+// NewSegmentTree2D builds from the matrix vals, every row must have the same length
+func NewSegmentTree2D(vals [][]interface{}, combine func(a interface{}, b interface{}) interface{}) SegmentTree2D {
+	t := SegmentTree2D{n: len(vals), combine: combine}
+	if t.n > 0 {
+		t.m = len(vals[0])
+	}
+	t.agg = make([][]interface{}, 2*t.n)
+	for i := range t.agg {
+		t.agg[i] = make([]interface{}, 2*t.m)
+	}
+	for i, row := range vals {
+		if len(row) != t.m {
+			panic("SegmentTree2D: rows have different length")
+		}
+		leaf := t.agg[t.n+i]
+		copy(leaf[t.m:], row)
+		for j := t.m - 1; j > 0; j-- {
+			leaf[j] = t.combineNil(leaf[2*j], leaf[2*j+1])
+		}
+	}
+	for i := t.n - 1; i > 0; i-- {
+		for j := 1; j < 2*t.m; j++ {
+			t.agg[i][j] = t.combineNil(t.agg[2*i][j], t.agg[2*i+1][j])
+		}
+	}
+	return t
+}
+
+// Dim returns the number of rows and columns
+func (t *SegmentTree2D) Dim() (int, int) {
+	return t.n, t.m
+}
+
+// Query returns the Combine of elements in rows [r1, r2) and columns [c1, c2),
+// panics if the rectangle is empty or out of bound
+func (t *SegmentTree2D) Query(r1 int, c1 int, r2 int, c2 int) interface{} {
+	if r1 < 0 || c1 < 0 || r2 > t.n || c2 > t.m || r1 >= r2 || c1 >= c2 {
+		panic("SegmentTree2D: range out of bound or empty")
+	}
+	var res interface{}
+	for l, r := r1+t.n, r2+t.n; l < r; l, r = l/2, r/2 {
+		if l&1 == 1 {
+			res = t.combineNil(res, t.queryRow(l, c1, c2))
+			l++
+		}
+		if r&1 == 1 {
+			r--
+			res = t.combineNil(res, t.queryRow(r, c1, c2))
+		}
+	}
+	return res
+}
+
+// Set the element at row i, column j to v
+func (t *SegmentTree2D) Set(i int, j int, v interface{}) {
+	if i < 0 || j < 0 || i >= t.n || j >= t.m {
+		panic("SegmentTree2D: index out of bound")
+	}
+	x := i + t.n
+	row := t.agg[x]
+	row[j+t.m] = v
+	for y := (j + t.m) / 2; y > 0; y /= 2 {
+		row[y] = t.combineNil(row[2*y], row[2*y+1])
+	}
+	for x /= 2; x > 0; x /= 2 {
+		for y := j + t.m; y > 0; y /= 2 {
+			t.agg[x][y] = t.combineNil(t.agg[2*x][y], t.agg[2*x+1][y])
+		}
+	}
+}
+
+// queryRow combines columns [c1, c2) of row node x
+func (t *SegmentTree2D) queryRow(x int, c1 int, c2 int) interface{} {
+	row := t.agg[x]
+	var res interface{}
+	for l, r := c1+t.m, c2+t.m; l < r; l, r = l/2, r/2 {
+		if l&1 == 1 {
+			res = t.combineNil(res, row[l])
+			l++
+		}
+		if r&1 == 1 {
+			r--
+			res = t.combineNil(res, row[r])
+		}
+	}
+	return res
+}
+
+// combineNil treats nil as the identity, which is the unused node of the bottom-up layout
+func (t *SegmentTree2D) combineNil(a interface{}, b interface{}) interface{} {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return t.combine(a, b)
+}
@@ -0,0 +1,153 @@
+package tree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// sum with range add
+var sumOps = SegmentOps{
+	Combine: func(a interface{}, b interface{}) interface{} {
+		return a.(int) + b.(int)
+	},
+	Apply: func(agg interface{}, tag interface{}, n int) interface{} {
+		return agg.(int) + tag.(int)*n
+	},
+	Compose: func(old interface{}, tag interface{}) interface{} {
+		return old.(int) + tag.(int)
+	},
+}
+
+// min with range assignment
+var minOps = SegmentOps{
+	Combine: func(a interface{}, b interface{}) interface{} {
+		if b.(int) < a.(int) {
+			return b
+		}
+		return a
+	},
+	Apply: func(agg interface{}, tag interface{}, n int) interface{} {
+		return tag
+	},
+	Compose: func(old interface{}, tag interface{}) interface{} {
+		return tag
+	},
+}
+
+func randomRange(n int) (int, int) {
+	l, r := rand.Intn(n), rand.Intn(n)
+	if l > r {
+		l, r = r, l
+	}
+	return l, r + 1
+}
+
+func TestSegmentTree(t *testing.T) {
+	for _, n := range []int{1, 2, 7, 100, 257} {
+		vals := make([]int, n)
+		ivals := make([]interface{}, n)
+		for i := range vals {
+			vals[i] = rand.Intn(100)
+			ivals[i] = vals[i]
+		}
+		sum := NewSegmentTree(ivals, sumOps)
+		sums := append([]int(nil), vals...)
+		min := NewSegmentTree(ivals, minOps)
+		mins := append([]int(nil), vals...)
+		for op := 0; op < 1000; op++ {
+			l, r := randomRange(n)
+			switch rand.Intn(3) {
+			case 0:
+				v := rand.Intn(100)
+				sum.Set(l, v)
+				sums[l] = v
+				min.Set(l, v)
+				mins[l] = v
+			case 1:
+				v := rand.Intn(100) - 50
+				sum.Update(l, r, v)
+				min.Update(l, r, v)
+				for i := l; i < r; i++ {
+					sums[i] += v
+					mins[i] = v
+				}
+			default:
+				s, m := 0, mins[l]
+				for i := l; i < r; i++ {
+					s += sums[i]
+					if mins[i] < m {
+						m = mins[i]
+					}
+				}
+				if got := sum.Query(l, r); got != s {
+					t.Fatalf("n %d sum [%d, %d) expected %d, got %v", n, l, r, s, got)
+				}
+				if got := min.Query(l, r); got != m {
+					t.Fatalf("n %d min [%d, %d) expected %d, got %v", n, l, r, m, got)
+				}
+			}
+		}
+		for i := range sums {
+			if got := sum.Get(i); got != sums[i] {
+				t.Fatalf("n %d get %d expected %d, got %v", n, i, sums[i], got)
+			}
+		}
+	}
+}
+
+func TestSegmentTree2D(t *testing.T) {
+	for _, dim := range [][2]int{{1, 1}, {3, 5}, {16, 9}, {31, 33}} {
+		n, m := dim[0], dim[1]
+		vals := make([][]int, n)
+		ivals := make([][]interface{}, n)
+		for i := range vals {
+			vals[i] = make([]int, m)
+			ivals[i] = make([]interface{}, m)
+			for j := range vals[i] {
+				vals[i][j] = rand.Intn(1000)
+				ivals[i][j] = vals[i][j]
+			}
+		}
+		st := NewSegmentTree2D(ivals, minOps.Combine)
+		for op := 0; op < 500; op++ {
+			r1, r2 := randomRange(n)
+			c1, c2 := randomRange(m)
+			if op%2 == 0 {
+				v := rand.Intn(1000)
+				st.Set(r1, c1, v)
+				vals[r1][c1] = v
+				continue
+			}
+			min := vals[r1][c1]
+			for i := r1; i < r2; i++ {
+				for j := c1; j < c2; j++ {
+					if vals[i][j] < min {
+						min = vals[i][j]
+					}
+				}
+			}
+			if got := st.Query(r1, c1, r2, c2); got != min {
+				t.Fatalf("%dx%d min [%d, %d)x[%d, %d) expected %d, got %v", n, m, r1, r2, c1, c2, min, got)
+			}
+		}
+	}
+}
+
+func TestSegmentTree_Panic(t *testing.T) {
+	st := NewSegmentTree([]interface{}{1, 2, 3}, SegmentOps{Combine: sumOps.Combine})
+	for _, f := range []func(){
+		func() { st.Query(2, 2) },
+		func() { st.Query(-1, 2) },
+		func() { st.Set(3, 0) },
+		func() { st.Update(0, 1, 1) }, // no Apply
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,240 @@
+package tree
+
+import (
+	"github.com/joexzh/dsa"
+	"github.com/joexzh/dsa/dict"
+)
+
+var _ dict.Dictionary = (*SplayTree)(nil)
+
+// SplayTree is a self-adjusting BST, every access splays the node to root top-down,
+// so the recently accessed keys are fast to access again, amortized o(logn).
+// Search, Get, Put and Remove modify the structure, heights are not maintained,
+// but subtree sizes and augmented values are.
+type SplayTree struct {
+	BST
+}
+
+func NewSplayTree() SplayTree {
+	t := SplayTree{BST: NewBST()}
+	t.updateHeight = func(*BinNode) {}
+	return t
+}
+
+// Search returns the node of key k, nil if not found.
+// The node of k, or the last one accessed if not found, becomes the root.
+func (t *SplayTree) Search(k dsa.Item) *BinNode {
+	t.splay(k)
+	if t.root != nil && equal(k, t.root.entry.K) {
+		return t.root
+	}
+	return nil
+}
+
+// Get value of key, nil if not found
+func (t *SplayTree) Get(k dsa.Item) interface{} {
+	if x := t.Search(k); x != nil {
+		return x.entry.V
+	}
+	return nil
+}
+
+// Put inserts a key-value pair as the new root. If k already exists, replaces the value and returns false.
+func (t *SplayTree) Put(k dsa.Item, v interface{}) bool {
+	if x := t.Search(k); x != nil {
+		x.entry.V = v
+		t.augmentAt(x)
+		return false
+	}
+	x := newBinNode(dsa.Entry{K: k, V: v}, nil)
+	if r := t.root; r != nil { // r is the closest one to k, split it into two sides of x
+		if k.Less(r.entry.K) {
+			attach(x, r.lc, r)
+			r.lc = nil
+		} else {
+			attach(x, r, r.rc)
+			r.rc = nil
+		}
+		t.augmentAt(r)
+	}
+	t.augmentAt(x)
+	t.root = x
+	t.size++
+	return true
+}
+
+// Remove the node of key k, returns false if not found
+func (t *SplayTree) Remove(k dsa.Item) bool {
+	x := t.Search(k)
+	if x == nil {
+		return false
+	}
+	l, r := x.lc, x.rc
+	x.lc, x.rc = nil, nil
+	t.root = nil
+	if l != nil {
+		l.parent = nil
+		t.root = l
+		t.splay(k) // the biggest one of left subtree becomes root, without right child
+		attach(t.root, t.root.lc, r)
+		t.augmentAt(t.root)
+	} else if r != nil {
+		r.parent = nil
+		t.root = r
+	}
+	t.size--
+	return true
+}
+
+// Join moves all entries of other into t, other becomes empty.
+// Every key of other must be bigger than keys of t, otherwise panic.
+func (t *SplayTree) Join(other *SplayTree) {
+	if other.Empty() {
+		return
+	}
+	if t.Empty() {
+		t.root, t.size = other.root, other.size
+		other.root, other.size = nil, 0
+		return
+	}
+	last := t.Last()
+	if !last.entry.K.Less(other.First().entry.K) {
+		panic("SplayTree.Join: keys of other must be bigger than keys of t")
+	}
+	t.splay(last.entry.K) // the biggest one becomes root, without right child
+	t.root.rc = other.root
+	other.root.parent = t.root
+	t.augmentAt(t.root)
+	t.size += other.size
+	other.root, other.size = nil, 0
+}
+
+// Split moves entries whose key is not less than k to a new tree and returns it,
+// t keeps the ones less than k.
+func (t *SplayTree) Split(k dsa.Item) SplayTree {
+	rt := NewSplayTree()
+	rt.augment = t.augment
+	t.splay(k)
+	if t.root == nil {
+		return rt
+	}
+	if t.root.entry.K.Less(k) {
+		rt.root = t.root.rc
+		t.root.rc = nil
+		t.augmentAt(t.root)
+	} else {
+		rt.root = t.root
+		t.root = rt.root.lc
+		rt.root.lc = nil
+		rt.augmentAt(rt.root)
+		if t.root != nil {
+			t.root.parent = nil
+		}
+	}
+	if rt.root != nil {
+		rt.root.parent = nil
+	}
+	rt.size = sizeOf(rt.root)
+	t.size -= rt.size
+	return rt
+}
+
+// Validate checks the BST properties
+func (t *SplayTree) Validate() error {
+	return t.validate()
+}
+
+// splay moves the node of k to root top-down, or the last accessed one if not found.
+// Nodes smaller than k are linked to the left tree, bigger ones to the right tree,
+// then they become the left and right subtrees of the new root.
+// Sizes and augmented values are updated along the changed paths, still o(depth).
+func (t *SplayTree) splay(k dsa.Item) {
+	x := t.root
+	if x == nil {
+		return
+	}
+	var lRoot, lMax, rRoot, rMin *BinNode // the left tree and its biggest node, the right tree and its smallest node
+	for {
+		if k.Less(x.entry.K) {
+			if x.lc == nil {
+				break
+			}
+			if k.Less(x.lc.entry.K) { // zig-zig, rotate right
+				y := x.lc
+				x.lc = y.rc
+				if y.rc != nil {
+					y.rc.parent = x
+				}
+				y.rc = x
+				x.parent = y
+				t.augmentAt(x) // children of x are final
+				x = y
+				if x.lc == nil {
+					break
+				}
+			}
+			if rMin == nil { // link x and its right subtree to the right tree
+				rRoot = x
+			} else {
+				rMin.lc = x
+				x.parent = rMin
+			}
+			rMin = x
+			x = x.lc
+		} else if x.entry.K.Less(k) {
+			if x.rc == nil {
+				break
+			}
+			if x.rc.entry.K.Less(k) { // zag-zag, rotate left
+				y := x.rc
+				x.rc = y.lc
+				if y.lc != nil {
+					y.lc.parent = x
+				}
+				y.lc = x
+				x.parent = y
+				t.augmentAt(x)
+				x = y
+				if x.rc == nil {
+					break
+				}
+			}
+			if lMax == nil { // link x and its left subtree to the left tree
+				lRoot = x
+			} else {
+				lMax.rc = x
+				x.parent = lMax
+			}
+			lMax = x
+			x = x.rc
+		} else {
+			break
+		}
+	}
+	// assemble, subtrees of x go to the left and right trees, which become subtrees of x
+	if lMax != nil {
+		lMax.rc = x.lc
+		if x.lc != nil {
+			x.lc.parent = lMax
+		}
+		x.lc = lRoot
+		lRoot.parent = x
+		for y := lMax; y != x; y = y.parent { // the right branch of the left tree
+			t.augmentAt(y)
+		}
+	}
+	if rMin != nil {
+		rMin.lc = x.rc
+		if x.rc != nil {
+			x.rc.parent = rMin
+		}
+		x.rc = rRoot
+		rRoot.parent = x
+		for y := rMin; y != x; y = y.parent { // the left branch of the right tree
+			t.augmentAt(y)
+		}
+	}
+	x.parent = nil
+	t.augmentAt(x)
+	t.root = x
+}
@@ -0,0 +1,146 @@
+package tree
+
+import (
+	"github.com/joexzh/dsa"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSplayTree_PutRemove(t *testing.T) {
+	st := NewSplayTree()
+	m := make(map[int]int)
+	for i, k := range randomKeys(1000, 700) {
+		_, exist := m[k]
+		if ok := st.Put(dsa.Int64(k), i); ok == exist {
+			t.Fatalf("put key %d expected %v, got %v", k, !exist, ok)
+		}
+		m[k] = i
+		if st.Root().Key() != dsa.Int64(k) {
+			t.Fatalf("expected root %d, got %v", k, st.Root().Key())
+		}
+	}
+	if err := st.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range m {
+		if got := st.Get(dsa.Int64(k)); got != v {
+			t.Fatalf("key %d expected %v, got %v", k, v, got)
+		}
+	}
+	if err := st.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range randomKeys(2000, 700) {
+		_, exist := m[k]
+		if ok := st.Remove(dsa.Int64(k)); ok != exist {
+			t.Fatalf("remove key %d expected %v, got %v", k, exist, ok)
+		}
+		delete(m, k)
+		if err := st.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if st.Size() != len(m) {
+		t.Fatalf("expected size %d, got %d", len(m), st.Size())
+	}
+}
+
+func TestSplayTree_JoinSplit(t *testing.T) {
+	for _, k := range []int{-1, 0, 1, 50, 137, 299, 300, 1000} {
+		st := NewSplayTree()
+		keys := randomKeys(300, 300)
+		for _, key := range keys {
+			st.Put(dsa.Int64(key), key)
+		}
+		expected := sortedUnique(keys)
+
+		right := st.Split(dsa.Int64(k))
+		if err := st.Validate(); err != nil {
+			t.Fatalf("split at %d, left: %v", k, err)
+		}
+		if err := right.Validate(); err != nil {
+			t.Fatalf("split at %d, right: %v", k, err)
+		}
+		i := 0
+		for i < len(expected) && expected[i] < k {
+			i++
+		}
+		if got := entryKeys(st.Traverse); !reflect.DeepEqual(expected[:i], got) {
+			t.Fatalf("split at %d, left expected %v, got %v", k, expected[:i], got)
+		}
+		if got := entryKeys(right.Traverse); !reflect.DeepEqual(expected[i:], got) {
+			t.Fatalf("split at %d, right expected %v, got %v", k, expected[i:], got)
+		}
+
+		st.Join(&right)
+		if err := st.Validate(); err != nil {
+			t.Fatalf("join at %d: %v", k, err)
+		}
+		if got := entryKeys(st.Traverse); !reflect.DeepEqual(expected, got) {
+			t.Fatalf("join at %d, expected %v, got %v", k, expected, got)
+		}
+	}
+}
+
+// skewed lookups, a few hot keys take most of the accesses
+func zipfKeys(n int) []dsa.Int64 {
+	r := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(r, 2, 1, benchSize-1)
+	perm := r.Perm(benchSize) // hot keys are scattered in the key space
+	keys := make([]dsa.Int64, n)
+	for i := range keys {
+		keys[i] = dsa.Int64(perm[z.Uint64()])
+	}
+	return keys
+}
+
+// in-order scans by Get, splay trees take amortized o(1) for each
+func sequentialKeys(n int) []dsa.Int64 {
+	keys := make([]dsa.Int64, n)
+	for i := range keys {
+		keys[i] = dsa.Int64(i % benchSize)
+	}
+	return keys
+}
+
+func benchmarkGet(b *testing.B, keys []dsa.Int64, put func(k dsa.Item), get func(k dsa.Item)) {
+	for _, i := range rand.New(rand.NewSource(2)).Perm(benchSize) {
+		put(dsa.Int64(i))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		get(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkSplayTree_SkewedGet(b *testing.B) {
+	st := NewSplayTree()
+	benchmarkGet(b, zipfKeys(benchSize), func(k dsa.Item) { st.Put(k, k) }, func(k dsa.Item) { st.Get(k) })
+}
+
+func BenchmarkAVLTree_SkewedGet(b *testing.B) {
+	avl := NewAVLTree()
+	benchmarkGet(b, zipfKeys(benchSize), func(k dsa.Item) { avl.Put(k, k) }, func(k dsa.Item) { avl.Get(k) })
+}
+
+func BenchmarkRBTree_SkewedGet(b *testing.B) {
+	rb := NewRBTree()
+	benchmarkGet(b, zipfKeys(benchSize), func(k dsa.Item) { rb.Put(k, k) }, func(k dsa.Item) { rb.Get(k) })
+}
+
+func BenchmarkSplayTree_SequentialGet(b *testing.B) {
+	st := NewSplayTree()
+	benchmarkGet(b, sequentialKeys(benchSize), func(k dsa.Item) { st.Put(k, k) }, func(k dsa.Item) { st.Get(k) })
+}
+
+func BenchmarkAVLTree_SequentialGet(b *testing.B) {
+	avl := NewAVLTree()
+	benchmarkGet(b, sequentialKeys(benchSize), func(k dsa.Item) { avl.Put(k, k) }, func(k dsa.Item) { avl.Get(k) })
+}
+
+func BenchmarkRBTree_SequentialGet(b *testing.B) {
+	rb := NewRBTree()
+	benchmarkGet(b, sequentialKeys(benchSize), func(k dsa.Item) { rb.Put(k, k) }, func(k dsa.Item) { rb.Get(k) })
+}
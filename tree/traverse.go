@@ -0,0 +1,140 @@
+package tree
+
+// Traversals of the subtree rooted at x.
+// The R suffixed ones are recursive, the others are iterative.
+
+func (x *BinNode) TravPreR(f func(x *BinNode)) {
+	if x == nil {
+		return
+	}
+	f(x)
+	x.lc.TravPreR(f)
+	x.rc.TravPreR(f)
+}
+
+func (x *BinNode) TravInR(f func(x *BinNode)) {
+	if x == nil {
+		return
+	}
+	x.lc.TravInR(f)
+	f(x)
+	x.rc.TravInR(f)
+}
+
+func (x *BinNode) TravPostR(f func(x *BinNode)) {
+	if x == nil {
+		return
+	}
+	x.lc.TravPostR(f)
+	x.rc.TravPostR(f)
+	f(x)
+}
+
+// TravPre visits along the left branch, and pushes right children to stack for later
+func (x *BinNode) TravPre(f func(x *BinNode)) {
+	stack := make([]*BinNode, 0)
+	for {
+		for ; x != nil; x = x.lc { // visit along left branch
+			f(x)
+			if x.rc != nil {
+				stack = append(stack, x.rc)
+			}
+		}
+		if len(stack) == 0 {
+			break
+		}
+		x = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+	}
+}
+
+// TravIn pushes the left branch to stack, then pops one to visit and turns to its right subtree
+func (x *BinNode) TravIn(f func(x *BinNode)) {
+	stack := make([]*BinNode, 0)
+	for {
+		for ; x != nil; x = x.lc {
+			stack = append(stack, x)
+		}
+		if len(stack) == 0 {
+			break
+		}
+		x = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		f(x)
+		x = x.rc
+	}
+}
+
+// TravPost visits x after both subtrees, by tracking the last visited node
+func (x *BinNode) TravPost(f func(x *BinNode)) {
+	if x == nil {
+		return
+	}
+	stack := []*BinNode{x}
+	var last *BinNode
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		if (top.lc == nil && top.rc == nil) || // leaf
+			(last != nil && (last == top.lc || last == top.rc)) { // children are done
+			f(top)
+			last = top
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if top.rc != nil {
+			stack = append(stack, top.rc)
+		}
+		if top.lc != nil {
+			stack = append(stack, top.lc)
+		}
+	}
+}
+
+// TravLevel visits level by level, from left to right
+func (x *BinNode) TravLevel(f func(x *BinNode)) {
+	if x == nil {
+		return
+	}
+	queue := []*BinNode{x}
+	for len(queue) > 0 {
+		x = queue[0]
+		queue = queue[1:]
+		f(x)
+		if x.lc != nil {
+			queue = append(queue, x.lc)
+		}
+		if x.rc != nil {
+			queue = append(queue, x.rc)
+		}
+	}
+}
+
+// Iterator walks nodes in order by Succ or Pred, the tree must not be modified during iteration.
+//
+//	for it := t.Iter(); it.Next(); {
+//		x := it.Node()
+//	}
+type Iterator struct {
+	curr    *BinNode
+	next    *BinNode
+	reverse bool
+}
+
+// Next moves to the next node, returns false if there is no more
+func (it *Iterator) Next() bool {
+	it.curr = it.next
+	if it.curr == nil {
+		return false
+	}
+	if it.reverse {
+		it.next = it.curr.Pred()
+	} else {
+		it.next = it.curr.Succ()
+	}
+	return true
+}
+
+// Node returns the current node
+func (it *Iterator) Node() *BinNode {
+	return it.curr
+}
@@ -0,0 +1,223 @@
+package tree
+
+import (
+	"fmt"
+	"github.com/joexzh/dsa"
+	"sort"
+)
+
+// Trie maps string keys to values, each node is a byte of keys, so keys sharing a prefix share the path.
+// Operations are o(len(key)*log(alphabet)), children are kept sorted for ordered iteration by bytes.
+// Entries passed to callbacks have dsa.String keys.
+type Trie struct {
+	root trieNode
+	size int
+}
+
+type trieNode struct {
+	labels []byte      // sorted
+	child  []*trieNode // child[i] is the one of labels[i]
+	has    bool        // a key ends here
+	value  interface{}
+}
+
+func NewTrie() Trie {
+	return Trie{}
+}
+
+func (t *Trie) Size() int {
+	return t.size
+}
+
+func (t *Trie) Empty() bool {
+	return t.size <= 0
+}
+
+// Get value of key, nil if not found
+func (t *Trie) Get(key string) interface{} {
+	if x := t.find(key); x != nil && x.has {
+		return x.value
+	}
+	return nil
+}
+
+// Contains returns true if key exists
+func (t *Trie) Contains(key string) bool {
+	x := t.find(key)
+	return x != nil && x.has
+}
+
+// Put inserts a key-value pair. If key already exists, replaces the value and returns false.
+func (t *Trie) Put(key string, v interface{}) bool {
+	x := &t.root
+	for i := 0; i < len(key); i++ {
+		j, ok := x.search(key[i])
+		if !ok {
+			x.insert(j, key[i], &trieNode{})
+		}
+		x = x.child[j]
+	}
+	x.value = v
+	if x.has {
+		return false
+	}
+	x.has = true
+	t.size++
+	return true
+}
+
+// Remove key, returns false if not found. Nodes no longer on the path of any key are removed.
+func (t *Trie) Remove(key string) bool {
+	path := make([]*trieNode, 0, len(key)+1)
+	x := &t.root
+	for i := 0; i < len(key); i++ {
+		path = append(path, x)
+		j, ok := x.search(key[i])
+		if !ok {
+			return false
+		}
+		x = x.child[j]
+	}
+	if !x.has {
+		return false
+	}
+	x.has, x.value = false, nil
+	t.size--
+	for i := len(key) - 1; i >= 0 && !x.has && len(x.labels) == 0; i-- { // prune bottom-up
+		x = path[i]
+		j, _ := x.search(key[i])
+		x.remove(j)
+	}
+	return true
+}
+
+// HasPrefix returns true if any key starts with prefix
+func (t *Trie) HasPrefix(prefix string) bool {
+	x := t.find(prefix)
+	return x != nil && (x.has || len(x.labels) > 0)
+}
+
+// KeysWithPrefix returns keys starting with prefix in ascending order
+func (t *Trie) KeysWithPrefix(prefix string) []string {
+	keys := make([]string, 0)
+	t.TraversePrefix(prefix, func(e dsa.Entry) {
+		keys = append(keys, string(e.K.(dsa.String)))
+	})
+	return keys
+}
+
+// TraversePrefix traverses entries whose key starts with prefix in ascending order
+func (t *Trie) TraversePrefix(prefix string, f func(e dsa.Entry)) {
+	if x := t.find(prefix); x != nil {
+		x.traverse([]byte(prefix), f)
+	}
+}
+
+// Traverse entries in ascending order
+func (t *Trie) Traverse(f func(e dsa.Entry)) {
+	t.root.traverse(nil, f)
+}
+
+// LongestPrefixOf returns the entry of the longest key which is a prefix of s, false if not found
+func (t *Trie) LongestPrefixOf(s string) (dsa.Entry, bool) {
+	x := &t.root
+	n := -1 // length of the longest key found
+	var v interface{}
+	for i := 0; ; i++ {
+		if x.has {
+			n, v = i, x.value
+		}
+		if i == len(s) {
+			break
+		}
+		j, ok := x.search(s[i])
+		if !ok {
+			break
+		}
+		x = x.child[j]
+	}
+	if n < 0 {
+		return dsa.Entry{}, false
+	}
+	return dsa.Entry{K: dsa.String(s[:n]), V: v}, true
+}
+
+// Validate checks labels are sorted, every leaf has a key, and the size
+func (t *Trie) Validate() error {
+	n := 0
+	var check func(x *trieNode, key []byte) error
+	check = func(x *trieNode, key []byte) error {
+		if x.has {
+			n++
+		}
+		if x != &t.root && !x.has && len(x.labels) == 0 {
+			return fmt.Errorf("node %q has no key and no child", key)
+		}
+		if len(x.labels) != len(x.child) {
+			return fmt.Errorf("node %q has %d labels but %d children", key, len(x.labels), len(x.child))
+		}
+		for i, c := range x.labels {
+			if i > 0 && x.labels[i-1] >= c {
+				return fmt.Errorf("node %q has unsorted labels %q", key, x.labels)
+			}
+			if err := check(x.child[i], append(key, c)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := check(&t.root, nil); err != nil {
+		return err
+	}
+	if n != t.size {
+		return fmt.Errorf("expected size %d, got %d", n, t.size)
+	}
+	return nil
+}
+
+// find returns the node of the path of key, nil if not exists
+func (t *Trie) find(key string) *trieNode {
+	x := &t.root
+	for i := 0; i < len(key); i++ {
+		j, ok := x.search(key[i])
+		if !ok {
+			return nil
+		}
+		x = x.child[j]
+	}
+	return x
+}
+
+// search returns the index of label c, or where it should be inserted and false
+func (x *trieNode) search(c byte) (int, bool) {
+	i := sort.Search(len(x.labels), func(i int) bool {
+		return x.labels[i] >= c
+	})
+	return i, i < len(x.labels) && x.labels[i] == c
+}
+
+func (x *trieNode) insert(i int, c byte, child *trieNode) {
+	x.labels = append(x.labels, 0)
+	copy(x.labels[i+1:], x.labels[i:])
+	x.labels[i] = c
+	x.child = append(x.child, nil)
+	copy(x.child[i+1:], x.child[i:])
+	x.child[i] = child
+}
+
+func (x *trieNode) remove(i int) {
+	x.labels = append(x.labels[:i], x.labels[i+1:]...)
+	copy(x.child[i:], x.child[i+1:])
+	x.child[len(x.child)-1] = nil
+	x.child = x.child[:len(x.child)-1]
+}
+
+// traverse the subtree x in pre-order, which is the ascending order of keys, key is the path to x
+func (x *trieNode) traverse(key []byte, f func(e dsa.Entry)) {
+	if x.has {
+		f(dsa.Entry{K: dsa.String(key), V: x.value})
+	}
+	for i, c := range x.labels {
+		x.child[i].traverse(append(key, c), f)
+	}
+}
@@ -0,0 +1,114 @@
+package tree
+
+import (
+	"github.com/joexzh/dsa"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type stringTree interface {
+	Size() int
+	Get(key string) interface{}
+	Contains(key string) bool
+	Put(key string, v interface{}) bool
+	Remove(key string) bool
+	HasPrefix(prefix string) bool
+	KeysWithPrefix(prefix string) []string
+	Traverse(f func(e dsa.Entry))
+	LongestPrefixOf(s string) (dsa.Entry, bool)
+	Validate() error
+}
+
+// randomString of small alphabet and length, so keys share prefixes a lot
+func randomString(maxLen int) string {
+	b := make([]byte, rand.Intn(maxLen+1))
+	for i := range b {
+		b[i] = "abc"[rand.Intn(3)]
+	}
+	return string(b)
+}
+
+func testStringTree(t *testing.T, name string, st stringTree) {
+	m := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		k := randomString(6)
+		_, exist := m[k]
+		if i%3 == 2 {
+			if ok := st.Remove(k); ok != exist {
+				t.Fatalf("%s: remove %q expected %v, got %v", name, k, exist, ok)
+			}
+			delete(m, k)
+		} else {
+			if ok := st.Put(k, i); ok == exist {
+				t.Fatalf("%s: put %q expected %v, got %v", name, k, !exist, ok)
+			}
+			m[k] = i
+		}
+		if i%100 == 0 {
+			if err := st.Validate(); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+	}
+	if err := st.Validate(); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if st.Size() != len(m) {
+		t.Fatalf("%s: expected size %d, got %d", name, len(m), st.Size())
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	got := make([]string, 0, len(m))
+	st.Traverse(func(e dsa.Entry) {
+		k := string(e.K.(dsa.String))
+		if e.V != m[k] {
+			t.Fatalf("%s: key %q expected value %d, got %v", name, k, m[k], e.V)
+		}
+		got = append(got, k)
+	})
+	if !reflect.DeepEqual(keys, got) {
+		t.Fatalf("%s: expected keys %q, got %q", name, keys, got)
+	}
+
+	for i := 0; i < 500; i++ {
+		q := randomString(7)
+		if v, ok := m[q]; st.Contains(q) != ok || (ok && st.Get(q) != v) || (!ok && st.Get(q) != nil) {
+			t.Fatalf("%s: get %q expected %v %v, got %v", name, q, v, ok, st.Get(q))
+		}
+		expected := make([]string, 0)
+		for _, k := range keys {
+			if strings.HasPrefix(k, q) {
+				expected = append(expected, k)
+			}
+		}
+		if got := st.KeysWithPrefix(q); !reflect.DeepEqual(expected, got) {
+			t.Fatalf("%s: keys with prefix %q expected %q, got %q", name, q, expected, got)
+		}
+		if st.HasPrefix(q) != (len(expected) > 0) {
+			t.Fatalf("%s: has prefix %q expected %v", name, q, len(expected) > 0)
+		}
+		longest := -1
+		for n := len(q); n >= 0; n-- {
+			if _, ok := m[q[:n]]; ok {
+				longest = n
+				break
+			}
+		}
+		e, ok := st.LongestPrefixOf(q)
+		if ok != (longest >= 0) || (ok && (e.K != dsa.String(q[:longest]) || e.V != m[q[:longest]])) {
+			t.Fatalf("%s: longest prefix of %q expected %d, got %v %v", name, q, longest, e, ok)
+		}
+	}
+}
+
+func TestTrie(t *testing.T) {
+	trie := NewTrie()
+	testStringTree(t, "Trie", &trie)
+}